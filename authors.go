@@ -3,100 +3,293 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
 )
 
+// memberships fetches and caches team and organization memberships
+// so that they can be shared between the authors of all sync jobs.
+type memberships struct {
+	client    githubClient
+	verbose   bool
+	ttl       time.Duration // Zero means memberships never expire.
+	fetchedAt time.Time
+	ids       map[string]string
+	teams     map[configTeam]map[string]bool
+	orgs      map[string]map[string]bool
+	public    map[string]bool // Whether user organizations are publicly visible.
+	progress  io.Writer       // Verbose output. Standard output if nil.
+}
+
+// out returns the writer for the verbose output.
+func (m *memberships) out() io.Writer {
+	if m.progress == nil {
+		return os.Stdout
+	}
+	return m.progress
+}
+
+func newMemberships(client githubClient, verbose bool) *memberships {
+	m := &memberships{
+		client:  client,
+		verbose: verbose,
+	}
+	m.reset(time.Now())
+
+	return m
+}
+
+func (m *memberships) reset(now time.Time) {
+	m.fetchedAt = now
+	m.ids = make(map[string]string)
+	m.teams = make(map[configTeam]map[string]bool)
+	m.orgs = make(map[string]map[string]bool)
+	m.public = make(map[string]bool)
+}
+
+// expire discards cached memberships if they are older than the TTL.
+func (m *memberships) expire(now time.Time) {
+	if m.ttl <= 0 || now.Sub(m.fetchedAt) < m.ttl {
+		return
+	}
+
+	if m.verbose {
+		fmt.Fprintln(m.out(), "Membership cache expired")
+	}
+	m.reset(now)
+}
+
+// teamMembers returns the members of the team.
+func (m *memberships) teamMembers(ctx context.Context, team configTeam) (map[string]bool, error) {
+	if members, ok := m.teams[team]; ok {
+		return members, nil
+	}
+
+	if m.verbose {
+		fmt.Fprintf(m.out(), "Fetching team members for %s:\n", team)
+		if err := m.printChildTeams(ctx, team); err != nil {
+			return nil, err
+		}
+	}
+
+	members := make(map[string]bool)
+	for u, err := range m.client.GetTeamMembers(ctx, team.owner, team.name, team.membership) {
+		if err != nil {
+			return nil, err
+		}
+
+		m.ids[u.Login] = u.ID
+		members[u.Login] = true
+
+		if m.verbose {
+			fmt.Fprintf(m.out(), "  - %s\n", u.Login)
+		}
+	}
+	m.teams[team] = members
+
+	return members, nil
+}
+
+// printChildTeams lists the child teams whose members are included in
+// or excluded from the team members according to the team membership.
+func (m *memberships) printChildTeams(ctx context.Context, team configTeam) error {
+	verb := "Including"
+	if team.membership == github.TeamMembershipImmediate {
+		verb = "Excluding"
+	}
+
+	for child, err := range m.client.GetChildTeams(ctx, team.owner, team.name) {
+		if err != nil {
+			return fmt.Errorf("error fetching child teams of %s: %w", team, err)
+		}
+		fmt.Fprintf(m.out(), "  %s child team %s/%s\n", verb, team.owner, child.Slug)
+	}
+
+	return nil
+}
+
+// userOrgs returns the organizations the user is a member of.
+// The result is guaranteed to be complete only with regard to orgs.
+func (m *memberships) userOrgs(ctx context.Context, login string, orgs []string) (map[string]bool, error) {
+	userOrgs, ok := m.orgs[login]
+	if !ok {
+		if m.verbose {
+			fmt.Fprintf(m.out(), "        Fetching organizations for %s\n", login)
+		}
+		userOrgs = make(map[string]bool)
+		for org, err := range m.client.GetUserOrganizations(ctx, login) {
+			if err != nil {
+				return nil, err
+			}
+			userOrgs[org.Login] = true
+		}
+		m.orgs[login] = userOrgs
+		m.public[login] = len(userOrgs) > 0
+	}
+
+	if m.public[login] {
+		return userOrgs, nil
+	}
+
+	// It's possible user profile is private so we can't get users orgs.
+	// We'll try to explicitly check for membership in the requested orgs.
+	for _, name := range orgs {
+		if _, checked := userOrgs[name]; checked {
+			continue
+		}
+
+		if m.verbose {
+			fmt.Fprintf(m.out(), "        Checking membership in %s for %s\n", name, login)
+		}
+		isMember, err := m.client.IsOrganizationMember(ctx, login, name)
+		if err != nil {
+			return nil, err
+		}
+		userOrgs[name] = isMember
+	}
+
+	return userOrgs, nil
+}
+
+// orgsSource describes where the organization memberships of the user came from.
+func (m *memberships) orgsSource(login string) string {
+	if m.public[login] {
+		return "public organizations"
+	}
+	return "organization membership check"
+}
+
+// userID returns the node ID of the user.
+func (m *memberships) userID(ctx context.Context, login string) (string, error) {
+	id, ok := m.ids[login]
+	if !ok {
+		if m.verbose {
+			fmt.Fprintf(m.out(), "        Fetching user ID for %s\n", login)
+		}
+		user, err := m.client.LookupUser(ctx, login)
+		if err != nil {
+			return "", err
+		}
+
+		id = user.ID
+		m.ids[login] = id
+	}
+
+	return id, nil
+}
+
 type authors struct {
-	client         githubClient
-	cfg            config
-	ids            map[string]string
+	memberships    *memberships
+	rules          configAuthors
+	verbose        bool
 	included       map[string]bool
 	excluded       map[string]bool
 	includedByTeam map[string]bool
 	excludedByTeam map[string]bool
 	includedByOrg  map[string]bool
 	excludedByOrg  map[string]bool
-	teams          map[configTeam]map[string]bool
-	orgs           map[string]map[string]bool
 }
 
-func NewAuthors(ctx context.Context, client githubClient, cfg config) (*authors, error) {
+func NewAuthors(ctx context.Context, memberships *memberships, rules configAuthors) (*authors, error) {
 	a := &authors{
-		client:         client,
-		cfg:            cfg,
-		ids:            make(map[string]string),
+		memberships:    memberships,
+		rules:          rules,
+		verbose:        memberships.verbose,
 		included:       make(map[string]bool),
 		excluded:       make(map[string]bool),
 		includedByTeam: make(map[string]bool),
 		excludedByTeam: make(map[string]bool),
 		includedByOrg:  make(map[string]bool),
 		excludedByOrg:  make(map[string]bool),
-		teams:          make(map[configTeam]map[string]bool),
-		orgs:           make(map[string]map[string]bool),
 	}
 
-	for _, user := range cfg.authors.include.users {
+	for _, user := range rules.include.users {
 		a.included[user] = true
 	}
 
-	for _, user := range cfg.authors.exclude.users {
+	for _, user := range rules.exclude.users {
 		a.excluded[user] = true
 	}
 
-	for _, team := range append(cfg.authors.include.teams, cfg.authors.exclude.teams...) {
-		if _, ok := a.teams[team]; ok {
-			break
-		}
-
-		if cfg.verbose {
-			fmt.Printf("Fetching team members for %s/%s:\n", team.owner, team.name)
-		}
-
-		a.teams[team] = make(map[string]bool)
-		members, err := client.GetTeamMembers(ctx, team.owner, team.name)
-		if err != nil {
+	for _, team := range append(rules.include.teams, rules.exclude.teams...) {
+		if _, err := memberships.teamMembers(ctx, team); err != nil {
 			return nil, err
 		}
-
-		for _, m := range members {
-			a.ids[m.Login] = m.ID
-			a.teams[team][m.Login] = true
-
-			if cfg.verbose {
-				fmt.Printf("  - %s\n", m.Login)
-			}
-		}
 	}
 
 	return a, nil
 }
 
 func (a *authors) Resolve(ctx context.Context, login string) (bool, error) {
+	return a.resolve(ctx, login, func(string, ...any) {})
+}
+
+// Explain resolves the author the same way as Resolve
+// and returns the trail of the rules that led to the decision.
+func (a *authors) Explain(ctx context.Context, login string) (bool, []string, error) {
+	var trail []string
+	included, err := a.resolve(ctx, login, func(format string, args ...any) {
+		trail = append(trail, fmt.Sprintf(format, args...))
+	})
+
+	return included, trail, err
+}
+
+func (a *authors) resolve(ctx context.Context, login string, explain func(format string, args ...any)) (bool, error) {
 	// By default, all authors are included.
-	if a.cfg.authors.include.empty() && a.cfg.authors.exclude.empty() {
+	if a.rules.include.empty() && a.rules.exclude.empty() {
+		explain("No author rules, all authors are included")
 		return true, nil
 	}
 
 	// Explicitly excluded.
 	if a.excluded[login] {
+		explain("Explicitly excluded by the users rule")
 		return false, nil
 	}
 
 	// Explicitly included.
 	if a.included[login] {
+		explain("Explicitly included by the users rule")
 		return true, nil
 	}
 
+	memberOf := func(ctx context.Context, login string, teams []configTeam) (configTeam, bool, error) {
+		for _, t := range teams {
+			members, err := a.memberships.teamMembers(ctx, t)
+			if err != nil {
+				return configTeam{}, false, err
+			}
+			if members[login] {
+				if a.verbose {
+					fmt.Fprintf(a.memberships.out(), "        %s is a member of %s\n", login, t)
+				}
+				return t, true, nil
+			}
+		}
+		return configTeam{}, false, nil
+	}
+
 	// Excluded by team.
-	if len(a.cfg.authors.exclude.teams) > 0 {
+	if len(a.rules.exclude.teams) > 0 {
 		excluded, ok := a.excludedByTeam[login]
 		if !ok {
-			for _, t := range a.cfg.authors.exclude.teams {
-				if a.teams[t][login] {
-					excluded = true
-					a.excludedByTeam[login] = true
-					break
-				}
+			team, member, err := memberOf(ctx, login, a.rules.exclude.teams)
+			if err != nil {
+				return false, err
+			}
+			if member {
+				explain("Excluded as a member of team %s", team)
+			} else {
+				explain("Not a member of any excluded team")
 			}
+			excluded = member
+			a.excludedByTeam[login] = excluded
+		} else if excluded {
+			explain("Excluded as a member of an excluded team")
 		}
 		if excluded {
 			return false, nil
@@ -104,78 +297,51 @@ func (a *authors) Resolve(ctx context.Context, login string) (bool, error) {
 	}
 
 	// Included by a team.
-	if len(a.cfg.authors.include.teams) > 0 {
+	if len(a.rules.include.teams) > 0 {
 		included, ok := a.includedByTeam[login]
 		if !ok {
-			for _, t := range a.cfg.authors.include.teams {
-				if a.teams[t][login] {
-					included = true
-					a.includedByTeam[login] = true
-					break
-				}
+			team, member, err := memberOf(ctx, login, a.rules.include.teams)
+			if err != nil {
+				return false, err
 			}
+			if member {
+				explain("Included as a member of team %s", team)
+			} else {
+				explain("Not a member of any included team")
+			}
+			included = member
+			a.includedByTeam[login] = included
+		} else if included {
+			explain("Included as a member of an included team")
 		}
 		if included {
 			return true, nil
 		}
 	}
 
-	getOrgs := func(ctx context.Context, login string, orgs []string) (map[string]bool, error) {
-		userOrgs, ok := a.orgs[login]
-		if !ok {
-			if a.cfg.verbose {
-				fmt.Printf("        Fetching organizations for %s\n", login)
-			}
-			ghOrgs, err := a.client.GetUserOrganizations(ctx, login)
-			if err != nil {
-				return nil, err
-			}
-
-			a.orgs[login] = make(map[string]bool)
-			for _, org := range ghOrgs {
-				a.orgs[login][org.Login] = true
-			}
-
-			// It's possible user profile is private so we can't get users orgs.
-			// We'll try to explicitly check for membership in the requested orgs.
-			if len(ghOrgs) == 0 {
-				for _, name := range orgs {
-					if a.cfg.verbose {
-						fmt.Printf("        Checking membership in %s for %s\n", name, login)
-					}
-					isMember, err := a.client.IsOrganizationMember(ctx, login, name)
-					if err != nil {
-						return nil, err
-					}
-					if isMember {
-						a.orgs[login][name] = true
-					}
-				}
-			}
-
-			return a.orgs[login], nil
-		}
-
-		return userOrgs, nil
-	}
-
 	// Excluded by an org.
-	if len(a.cfg.authors.exclude.orgs) > 0 {
+	if len(a.rules.exclude.orgs) > 0 {
 		excluded, ok := a.excludedByOrg[login]
 		if !ok {
-			orgs, err := getOrgs(ctx, login, a.cfg.authors.exclude.orgs)
+			orgs, err := a.memberships.userOrgs(ctx, login, a.rules.exclude.orgs)
 			if err != nil {
 				return false, err
 			}
 
 			a.excludedByOrg[login] = false
-			for _, org := range a.cfg.authors.exclude.orgs {
+			for _, org := range a.rules.exclude.orgs {
 				if orgs[org] {
 					excluded = true
 					a.excludedByOrg[login] = true
+					explain("Excluded as a member of organization %s (%s)", org, a.memberships.orgsSource(login))
 					break
 				}
 			}
+			if !excluded {
+				explain("Not a member of any excluded organization (%s)", a.memberships.orgsSource(login))
+			}
+		} else if excluded {
+			explain("Excluded as a member of an excluded organization")
 		}
 		if excluded {
 			return false, nil
@@ -183,45 +349,43 @@ func (a *authors) Resolve(ctx context.Context, login string) (bool, error) {
 	}
 
 	// Included by an org.
-	if len(a.cfg.authors.include.orgs) > 0 {
+	if len(a.rules.include.orgs) > 0 {
 		included, ok := a.includedByOrg[login]
 		if !ok {
-			orgs, err := getOrgs(ctx, login, a.cfg.authors.include.orgs)
+			orgs, err := a.memberships.userOrgs(ctx, login, a.rules.include.orgs)
 			if err != nil {
 				return false, err
 			}
 
 			a.includedByOrg[login] = false
-			for _, org := range a.cfg.authors.include.orgs {
+			for _, org := range a.rules.include.orgs {
 				if orgs[org] {
 					included = true
 					a.includedByOrg[login] = true
+					explain("Included as a member of organization %s (%s)", org, a.memberships.orgsSource(login))
 					break
 				}
 			}
+			if !included {
+				explain("Not a member of any included organization (%s)", a.memberships.orgsSource(login))
+			}
+		} else if included {
+			explain("Included as a member of an included organization")
 		}
 		if included {
 			return true, nil
 		}
 	}
 
-	return a.cfg.authors.include.empty(), nil
+	if a.rules.include.empty() {
+		explain("No include rules, included by default")
+		return true, nil
+	}
+
+	explain("Not matched by any include rule")
+	return false, nil
 }
 
 func (a *authors) GetID(ctx context.Context, login string) (string, error) {
-	id, ok := a.ids[login]
-	if !ok {
-		if a.cfg.verbose {
-			fmt.Printf("        Fetching user ID for %s\n", login)
-		}
-		user, err := a.client.LookupUser(ctx, login)
-		if err != nil {
-			return "", err
-		}
-
-		id = user.ID
-		a.ids[login] = id
-	}
-
-	return id, nil
+	return a.memberships.userID(ctx, login)
 }
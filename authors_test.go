@@ -2,17 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
 	"testing"
+	"time"
 
 	"github.com/pmatseykanets/prsync/github"
 )
 
 func TestAuthorsIsNoRules(t *testing.T) {
 	ctx := context.Background()
-	cfg := config{}
+	job := configJob{}
 	client := &fakeGithubClient{}
 
-	authors, err := NewAuthors(ctx, client, cfg)
+	authors, err := NewAuthors(ctx, newMemberships(client, false), job.authors)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -28,34 +32,34 @@ func TestAuthorsIsNoRules(t *testing.T) {
 
 func TestAuthorsIsExplicitlyIncluded(t *testing.T) {
 	ctx := context.Background()
-	cfg := config{
+	job := configJob{
 		authors: configAuthors{
 			include: configAuthorRules{
 				users: []string{"user"},
 			},
 			exclude: configAuthorRules{
-				teams: []configTeam{{"org1", "team1"}},
+				teams: []configTeam{{owner: "org1", name: "team1"}},
 			},
 		},
 	}
 	client := &fakeGithubClient{
-		GetTeamMembersFunc: func(ctx context.Context, owner, name string) ([]github.User, error) {
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
 			switch name {
 			case "team1":
-				return []github.User{
-					{ID: "user", Login: "user"},
-				}, nil
+				return seqOf(
+					github.User{ID: "user", Login: "user"},
+				)
 			default:
-				return nil, nil
+				return seqOf[github.User]()
 			}
 		},
-		GetUserOrganizationsFunc: func(ctx context.Context, login string) ([]github.Organization, error) {
+		GetUserOrganizationsFunc: func(ctx context.Context, login string) iter.Seq2[*github.Organization, error] {
 			t.Errorf("Unexpected call to GetUserOrganizations for %s", login)
-			return nil, nil
+			return seqOf[github.Organization]()
 		},
 	}
 
-	authors, err := NewAuthors(ctx, client, cfg)
+	authors, err := NewAuthors(ctx, newMemberships(client, false), job.authors)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -81,12 +85,12 @@ func TestAuthorsIsExplicitlyIncluded(t *testing.T) {
 
 func TestAuthorsIsIncludedByATeam(t *testing.T) {
 	ctx := context.Background()
-	cfg := config{
+	job := configJob{
 		authors: configAuthors{
 			include: configAuthorRules{
 				teams: []configTeam{
-					{"org1", "team1"},
-					{"org1", "team2"},
+					{owner: "org1", name: "team1"},
+					{owner: "org1", name: "team2"},
 				},
 			},
 			exclude: configAuthorRules{
@@ -96,30 +100,30 @@ func TestAuthorsIsIncludedByATeam(t *testing.T) {
 		},
 	}
 	client := &fakeGithubClient{
-		GetTeamMembersFunc: func(ctx context.Context, owner, name string) ([]github.User, error) {
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
 			switch name {
 			case "team1":
-				return []github.User{
-					{ID: "user", Login: "user"},
-					{ID: "user1", Login: "user1"},
-				}, nil
+				return seqOf(
+					github.User{ID: "user", Login: "user"},
+					github.User{ID: "user1", Login: "user1"},
+				)
 			case "team2":
-				return []github.User{
-					{ID: "user2", Login: "user2"},
-					{ID: "user3", Login: "user3"},
-				}, nil
+				return seqOf(
+					github.User{ID: "user2", Login: "user2"},
+					github.User{ID: "user3", Login: "user3"},
+				)
 			default:
-				return nil, nil
+				return seqOf[github.User]()
 			}
 		},
 		LookupUserFunc: func(ctx context.Context, login string) (*github.User, error) {
 			return &github.User{ID: login, Login: login}, nil
 		},
-		GetUserOrganizationsFunc: func(ctx context.Context, login string) ([]github.Organization, error) {
+		GetUserOrganizationsFunc: func(ctx context.Context, login string) iter.Seq2[*github.Organization, error] {
 			if login != "user4" {
 				t.Errorf("Unexpected call to GetUserOrganizations for %s", login)
 			}
-			return nil, nil
+			return seqOf[github.Organization]()
 		},
 		IsOrganizationMemberFunc: func(ctx context.Context, login, org string) (bool, error) {
 			if login != "user4" {
@@ -129,7 +133,7 @@ func TestAuthorsIsIncludedByATeam(t *testing.T) {
 		},
 	}
 
-	authors, err := NewAuthors(ctx, client, cfg)
+	authors, err := NewAuthors(ctx, newMemberships(client, false), job.authors)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,3 +165,194 @@ func TestAuthorsIsIncludedByATeam(t *testing.T) {
 		t.Fatalf("Expected %t, got %t", want, got)
 	}
 }
+
+func TestAuthorsShareMemberships(t *testing.T) {
+	ctx := context.Background()
+	var teamCalls, orgCalls int
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			teamCalls++
+			return seqOf(github.User{ID: "user", Login: "user"})
+		},
+		GetUserOrganizationsFunc: func(ctx context.Context, login string) iter.Seq2[*github.Organization, error] {
+			orgCalls++
+			return seqOf[github.Organization]()
+		},
+		IsOrganizationMemberFunc: func(ctx context.Context, login, org string) (bool, error) {
+			return org == "org2", nil
+		},
+	}
+	memberships := newMemberships(client, false)
+
+	job1 := configJob{
+		authors: configAuthors{
+			include: configAuthorRules{
+				teams: []configTeam{{owner: "org1", name: "team1"}},
+				orgs:  []string{"org1"},
+			},
+		},
+	}
+	job2 := configJob{
+		authors: configAuthors{
+			include: configAuthorRules{
+				orgs: []string{"org2"},
+			},
+			exclude: configAuthorRules{
+				teams: []configTeam{{owner: "org1", name: "team1"}},
+			},
+		},
+	}
+
+	authors1, err := NewAuthors(ctx, memberships, job1.authors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors2, err := NewAuthors(ctx, memberships, job2.authors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 1, teamCalls; want != got {
+		t.Fatalf("Expected %d team calls, got %d", want, got)
+	}
+
+	// A user included by a team in one job and excluded in the other.
+	isAuthor, err := authors1.Resolve(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := true, isAuthor; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+	isAuthor, err = authors2.Resolve(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := false, isAuthor; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+
+	// A user with private organizations is checked against the orgs of each job.
+	isAuthor, err = authors1.Resolve(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := false, isAuthor; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+	isAuthor, err = authors2.Resolve(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := true, isAuthor; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+	if want, got := 1, orgCalls; want != got {
+		t.Fatalf("Expected %d organization calls, got %d", want, got)
+	}
+}
+
+func TestMembershipsExpire(t *testing.T) {
+	ctx := context.Background()
+	var teamCalls int
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			teamCalls++
+			return seqOf(github.User{ID: "user", Login: "user"})
+		},
+	}
+	memberships := newMemberships(client, false)
+	memberships.ttl = time.Hour
+	team := configTeam{owner: "org1", name: "team1"}
+
+	if _, err := memberships.teamMembers(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still fresh.
+	memberships.expire(memberships.fetchedAt.Add(time.Minute))
+	if _, err := memberships.teamMembers(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 1, teamCalls; want != got {
+		t.Fatalf("Expected %d team calls, got %d", want, got)
+	}
+
+	// Expired.
+	memberships.expire(memberships.fetchedAt.Add(time.Hour))
+	if _, err := memberships.teamMembers(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, teamCalls; want != got {
+		t.Fatalf("Expected %d team calls, got %d", want, got)
+	}
+}
+
+func TestMembershipsTeamMembersPages(t *testing.T) {
+	ctx := context.Background()
+	users := make([]github.User, 250)
+	for i := range users {
+		users[i] = github.User{ID: fmt.Sprintf("user%d", i), Login: fmt.Sprintf("user%d", i)}
+	}
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			if name == "broken" {
+				return func(yield func(*github.User, error) bool) {
+					if !yield(&users[0], nil) {
+						return
+					}
+					yield(nil, errors.New("page failed"))
+				}
+			}
+			return seqOf(users...)
+		},
+	}
+	memberships := newMemberships(client, false)
+
+	members, err := memberships.teamMembers(ctx, configTeam{owner: "org1", name: "team1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := len(users), len(members); want != got {
+		t.Fatalf("Expected %d members, got %d", want, got)
+	}
+	if want, got := true, members["user249"]; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+
+	if _, err := memberships.teamMembers(ctx, configTeam{owner: "org1", name: "broken"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	// A partially fetched team is not cached.
+	if _, ok := memberships.teams[configTeam{owner: "org1", name: "broken"}]; ok {
+		t.Fatal("Expected the team not to be cached")
+	}
+}
+
+func TestMembershipsTeamMembership(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			if membership == github.TeamMembershipImmediate {
+				return seqOf(github.User{ID: "parent", Login: "parent"})
+			}
+			return seqOf(github.User{ID: "parent", Login: "parent"}, github.User{ID: "child", Login: "child"})
+		},
+	}
+	memberships := newMemberships(client, false)
+
+	all, err := memberships.teamMembers(ctx, configTeam{owner: "org1", name: "team1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	immediate, err := memberships.teamMembers(ctx, configTeam{owner: "org1", name: "team1", membership: github.TeamMembershipImmediate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := true, all["child"]; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+	if want, got := false, immediate["child"]; want != got {
+		t.Fatalf("Expected %t, got %t", want, got)
+	}
+}
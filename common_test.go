@@ -8,45 +8,120 @@ import (
 )
 
 type fakeGithubClient struct {
-	AddAssigneeToPullRequestFunc     func(ctx context.Context, prID, userID string) error
-	AddPullRequestToProjectFunc      func(ctx context.Context, projectID, prID string) error
-	DeletePullRequestFromProjectFunc func(ctx context.Context, projectID, projectItemID string) error
-	GetProjectFunc                   func(ctx context.Context, owner string, number int) (*github.Project, error)
-	GetProjectPullRequestsFunc       func(ctx context.Context, owner string, number int) iter.Seq2[*github.PullRequest, error]
-	GetRepositoryPullRequestsFunc    func(ctx context.Context, owner string, name string, states []github.PullRequestState) iter.Seq2[*github.PullRequest, error]
-	GetTeamMembersFunc               func(ctx context.Context, owner, name string) ([]github.User, error)
-	GetUserOrganizationsFunc         func(ctx context.Context, login string) ([]github.Organization, error)
-	LookupUserFunc                   func(ctx context.Context, login string) (*github.User, error)
-	IsOrganizationMemberFunc         func(ctx context.Context, login, org string) (bool, error)
+	AddAssigneeToIssueFunc          func(ctx context.Context, issueID, userID string) error
+	AddAssigneeToPullRequestFunc    func(ctx context.Context, prID, userID string) error
+	AddIssueToProjectFunc           func(ctx context.Context, projectID, issueID string) (string, error)
+	AddPullRequestToProjectFunc     func(ctx context.Context, projectID, prID string) (string, error)
+	ArchiveProjectItemFunc          func(ctx context.Context, projectID, projectItemID string) error
+	DeleteProjectItemFunc           func(ctx context.Context, projectID, projectItemID string) error
+	GetChildTeamsFunc               func(ctx context.Context, org, team string) iter.Seq2[*github.Team, error]
+	GetOwnerRepositoriesFunc        func(ctx context.Context, owner string) iter.Seq2[*github.Repository, error]
+	GetProjectFunc                  func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error)
+	GetProjectFieldsFunc            func(ctx context.Context, projectID string) ([]github.ProjectField, error)
+	GetProjectIssuesFunc            func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.Issue, error]
+	GetProjectPullRequestsFunc      func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.PullRequest, error]
+	GetPullRequestFunc              func(ctx context.Context, owner, name string, number int) (*github.PullRequest, error)
+	GetRepositoryFunc               func(ctx context.Context, owner, name string) (*github.Repository, error)
+	GetRepositoryIssuesFunc         func(ctx context.Context, owner string, name string, states []github.IssueState) iter.Seq2[*github.Issue, error]
+	GetRepositoryPullRequestsFunc   func(ctx context.Context, owner string, name string, states []github.PullRequestState) iter.Seq2[*github.PullRequest, error]
+	GetTeamFunc                     func(ctx context.Context, org, team string) (*github.Team, error)
+	GetTeamMembersFunc              func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error]
+	GetTeamRepositoriesFunc         func(ctx context.Context, org, team string) iter.Seq2[*github.Repository, error]
+	GetUserOrganizationsFunc        func(ctx context.Context, login string) iter.Seq2[*github.Organization, error]
+	LookupUserFunc                  func(ctx context.Context, login string) (*github.User, error)
+	IsOrganizationMemberFunc        func(ctx context.Context, login, org string) (bool, error)
+	RateLimitFunc                   func() github.RateLimitUsage
+	SearchRepositoriesFunc          func(ctx context.Context, query string) iter.Seq2[*github.Repository, error]
+	UpdateProjectItemFieldValueFunc func(ctx context.Context, projectID, itemID, fieldID string, value github.ProjectFieldValue) error
 }
 
+func (c *fakeGithubClient) AddAssigneeToIssue(ctx context.Context, issueID, userID string) error {
+	if c.AddAssigneeToIssueFunc != nil {
+		return c.AddAssigneeToIssueFunc(ctx, issueID, userID)
+	}
+	return nil
+}
 func (c *fakeGithubClient) AddAssigneeToPullRequest(ctx context.Context, prID, userID string) error {
 	if c.AddAssigneeToPullRequestFunc != nil {
 		return c.AddAssigneeToPullRequestFunc(ctx, prID, userID)
 	}
 	return nil
 }
-func (c *fakeGithubClient) AddPullRequestToProject(ctx context.Context, projectID, prID string) error {
+func (c *fakeGithubClient) AddIssueToProject(ctx context.Context, projectID, issueID string) (string, error) {
+	if c.AddIssueToProjectFunc != nil {
+		return c.AddIssueToProjectFunc(ctx, projectID, issueID)
+	}
+	return "", nil
+}
+func (c *fakeGithubClient) AddPullRequestToProject(ctx context.Context, projectID, prID string) (string, error) {
 	if c.AddPullRequestToProjectFunc != nil {
 		return c.AddPullRequestToProjectFunc(ctx, projectID, prID)
 	}
+	return "", nil
+}
+func (c *fakeGithubClient) ArchiveProjectItem(ctx context.Context, projectID, projectItemID string) error {
+	if c.ArchiveProjectItemFunc != nil {
+		return c.ArchiveProjectItemFunc(ctx, projectID, projectItemID)
+	}
+	return nil
+}
+func (c *fakeGithubClient) DeleteProjectItem(ctx context.Context, projectID, projectItemID string) error {
+	if c.DeleteProjectItemFunc != nil {
+		return c.DeleteProjectItemFunc(ctx, projectID, projectItemID)
+	}
 	return nil
 }
-func (c *fakeGithubClient) DeletePullRequestFromProject(ctx context.Context, projectID, projectItemID string) error {
-	if c.DeletePullRequestFromProjectFunc != nil {
-		return c.DeletePullRequestFromProjectFunc(ctx, projectID, projectItemID)
+func (c *fakeGithubClient) GetChildTeams(ctx context.Context, org, team string) iter.Seq2[*github.Team, error] {
+	if c.GetChildTeamsFunc != nil {
+		return c.GetChildTeamsFunc(ctx, org, team)
+	}
+	return seqOf[github.Team]()
+}
+func (c *fakeGithubClient) GetOwnerRepositories(ctx context.Context, owner string) iter.Seq2[*github.Repository, error] {
+	if c.GetOwnerRepositoriesFunc != nil {
+		return c.GetOwnerRepositoriesFunc(ctx, owner)
 	}
 	return nil
 }
-func (c *fakeGithubClient) GetProject(ctx context.Context, owner string, number int) (*github.Project, error) {
+func (c *fakeGithubClient) GetProject(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
 	if c.GetProjectFunc != nil {
-		return c.GetProjectFunc(ctx, owner, number)
+		return c.GetProjectFunc(ctx, owner, ownerType, number)
 	}
 	return nil, nil
 }
-func (c *fakeGithubClient) GetProjectPullRequests(ctx context.Context, owner string, number int) iter.Seq2[*github.PullRequest, error] {
+func (c *fakeGithubClient) GetProjectFields(ctx context.Context, projectID string) ([]github.ProjectField, error) {
+	if c.GetProjectFieldsFunc != nil {
+		return c.GetProjectFieldsFunc(ctx, projectID)
+	}
+	return nil, nil
+}
+func (c *fakeGithubClient) GetProjectIssues(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.Issue, error] {
+	if c.GetProjectIssuesFunc != nil {
+		return c.GetProjectIssuesFunc(ctx, owner, ownerType, number)
+	}
+	return nil
+}
+func (c *fakeGithubClient) GetProjectPullRequests(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.PullRequest, error] {
 	if c.GetProjectPullRequestsFunc != nil {
-		return c.GetProjectPullRequestsFunc(ctx, owner, number)
+		return c.GetProjectPullRequestsFunc(ctx, owner, ownerType, number)
+	}
+	return nil
+}
+func (c *fakeGithubClient) GetPullRequest(ctx context.Context, owner, name string, number int) (*github.PullRequest, error) {
+	if c.GetPullRequestFunc != nil {
+		return c.GetPullRequestFunc(ctx, owner, name, number)
+	}
+	return nil, nil
+}
+func (c *fakeGithubClient) GetRepository(ctx context.Context, owner, name string) (*github.Repository, error) {
+	if c.GetRepositoryFunc != nil {
+		return c.GetRepositoryFunc(ctx, owner, name)
+	}
+	return nil, nil
+}
+func (c *fakeGithubClient) GetRepositoryIssues(ctx context.Context, owner string, name string, states []github.IssueState) iter.Seq2[*github.Issue, error] {
+	if c.GetRepositoryIssuesFunc != nil {
+		return c.GetRepositoryIssuesFunc(ctx, owner, name, states)
 	}
 	return nil
 }
@@ -56,17 +131,29 @@ func (c *fakeGithubClient) GetRepositoryPullRequests(ctx context.Context, owner
 	}
 	return nil
 }
-func (c *fakeGithubClient) GetTeamMembers(ctx context.Context, owner, name string) ([]github.User, error) {
-	if c.GetTeamMembersFunc != nil {
-		return c.GetTeamMembersFunc(ctx, owner, name)
+func (c *fakeGithubClient) GetTeam(ctx context.Context, org, team string) (*github.Team, error) {
+	if c.GetTeamFunc != nil {
+		return c.GetTeamFunc(ctx, org, team)
 	}
 	return nil, nil
 }
-func (c *fakeGithubClient) GetUserOrganizations(ctx context.Context, login string) ([]github.Organization, error) {
+func (c *fakeGithubClient) GetTeamMembers(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+	if c.GetTeamMembersFunc != nil {
+		return c.GetTeamMembersFunc(ctx, owner, name, membership)
+	}
+	return seqOf[github.User]()
+}
+func (c *fakeGithubClient) GetTeamRepositories(ctx context.Context, org, team string) iter.Seq2[*github.Repository, error] {
+	if c.GetTeamRepositoriesFunc != nil {
+		return c.GetTeamRepositoriesFunc(ctx, org, team)
+	}
+	return nil
+}
+func (c *fakeGithubClient) GetUserOrganizations(ctx context.Context, login string) iter.Seq2[*github.Organization, error] {
 	if c.GetUserOrganizationsFunc != nil {
 		return c.GetUserOrganizationsFunc(ctx, login)
 	}
-	return nil, nil
+	return seqOf[github.Organization]()
 }
 func (c *fakeGithubClient) LookupUser(ctx context.Context, login string) (*github.User, error) {
 	if c.LookupUserFunc != nil {
@@ -80,3 +167,32 @@ func (c *fakeGithubClient) IsOrganizationMember(ctx context.Context, login, org
 	}
 	return false, nil
 }
+func (c *fakeGithubClient) RateLimit() github.RateLimitUsage {
+	if c.RateLimitFunc != nil {
+		return c.RateLimitFunc()
+	}
+	return github.RateLimitUsage{}
+}
+func (c *fakeGithubClient) SearchRepositories(ctx context.Context, query string) iter.Seq2[*github.Repository, error] {
+	if c.SearchRepositoriesFunc != nil {
+		return c.SearchRepositoriesFunc(ctx, query)
+	}
+	return nil
+}
+func (c *fakeGithubClient) UpdateProjectItemFieldValue(ctx context.Context, projectID, itemID, fieldID string, value github.ProjectFieldValue) error {
+	if c.UpdateProjectItemFieldValueFunc != nil {
+		return c.UpdateProjectItemFieldValueFunc(ctx, projectID, itemID, fieldID, value)
+	}
+	return nil
+}
+
+// seqOf returns an iterator over the items as the paginating client methods do.
+func seqOf[T any](items ...T) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for _, item := range items {
+			if !yield(&item, nil) {
+				return
+			}
+		}
+	}
+}
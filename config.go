@@ -4,21 +4,28 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pmatseykanets/prsync/github"
 	"gopkg.in/yaml.v3"
 )
 
 type configProject struct {
-	owner  string
-	number int
+	owner     string
+	ownerType github.ProjectOwnerType
+	number    int
 }
 
 type configTeam struct {
-	owner string
-	name  string
+	owner      string
+	name       string
+	membership github.TeamMembership // Empty means ALL.
 }
 
 func (t configTeam) String() string {
@@ -45,46 +52,149 @@ func (r *configAuthorRules) empty() bool {
 	return len(r.users) == 0 && len(r.teams) == 0 && len(r.orgs) == 0
 }
 
-type config struct {
-	path         string
-	githubURL    string
+// deleteMode defines how pull requests are removed from the project.
+type deleteMode string
+
+const (
+	deleteModeDelete  deleteMode = "delete"
+	deleteModeArchive deleteMode = "archive"
+)
+
+type configJob struct {
+	name         string
 	project      configProject
-	repos        []configRepo
+	repoSources  []configRepoSource
+	excludeRepos []string
+	archived     bool
+	forks        bool
+	repos        []configRepo // Resolved from repoSources at runtime.
 	authors      configAuthors
+	reviewers    configReviewers
 	pullRequests struct {
 		add struct {
-			states       []github.PullRequestState
+			states          []github.PullRequestState
+			assignAuthor    bool
+			drafts          bool
+			labels          configPatterns
+			baseBranches    configPatterns
+			headBranches    configPatterns
+			createdWithin   time.Duration
+			reviewDecisions []github.ReviewDecision
+			reviews         []github.ReviewState // Any reviewer's latest review.
+			reviewRequested *bool                // Has pending review requests.
+			checks          []github.CheckState
+			fields          map[string]string
+		}
+		update struct {
+			rules      []configUpdateRule
+			allAuthors bool
+		}
+		delete struct {
+			states          []github.PullRequestState
+			drafts          bool
+			labels          configPatterns
+			baseBranches    configPatterns
+			headBranches    configPatterns
+			staleAfter      time.Duration
+			reviewDecisions []github.ReviewDecision
+			reviews         []github.ReviewState // Any reviewer's latest review.
+			reviewRequested *bool                // Has pending review requests.
+			checks          []github.CheckState
+			allAuthors      bool
+			mode            deleteMode
+		}
+	}
+	issues struct {
+		enabled bool
+		add     struct {
+			states       []github.IssueState
 			assignAuthor bool
-			drafts       bool
+			fields       map[string]string
 		}
 		delete struct {
-			states     []github.PullRequestState
-			drafts     bool
+			states     []github.IssueState
 			allAuthors bool
+			mode       deleteMode
 		}
 	}
-	dryRun  bool
-	verbose bool
 }
 
-type configFile struct {
-	GitHub struct {
-		URL string `yaml:"url"`
+// configGitHubApp is a GitHub App installation to authenticate as.
+type configGitHubApp struct {
+	id             int64
+	installationID int64
+	privateKeyPath string
+}
+
+type config struct {
+	path               string
+	githubURL          string
+	githubMaxAttempts  int              // How many times a request failing with transient errors is attempted.
+	githubApp          *configGitHubApp // Authenticate as a GitHub App installation instead of with a token.
+	githubTokenCommand string           // A shell command printing the token.
+	githubTokenTTL     time.Duration    // How long a token read from a file or a command is used.
+	tokenFile          string           // A file with the token.
+	jobs               []configJob
+	dryRun             bool
+	verbose            bool
+	report             *reporter // Machine readable output, if any.
+	progress           io.Writer // Human readable progress output. Standard output if nil.
+}
+
+// out returns the writer for the progress output.
+func (c config) out() io.Writer {
+	if c.progress == nil {
+		return os.Stdout
+	}
+	return c.progress
+}
+
+type configFilePatterns struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// configFileTeam is either a <owner>/<name> string
+// or a mapping with the name and the membership.
+type configFileTeam struct {
+	Name       string `yaml:"name"`
+	Membership string `yaml:"membership"`
+}
+
+func (t *configFileTeam) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.Name)
 	}
-	Project string   `yaml:"project"`
-	Repos   []string `yaml:"repos"`
-	Authors struct {
+
+	type plain configFileTeam
+	return value.Decode((*plain)(t))
+}
+
+type configFileJob struct {
+	Name            string   `yaml:"name"`
+	Project         string   `yaml:"project"`
+	Repos           []string `yaml:"repos"`
+	ExcludeRepos    []string `yaml:"excludeRepos"`
+	IncludeArchived bool     `yaml:"includeArchivedRepos"`
+	IncludeForks    bool     `yaml:"includeForkRepos"`
+	Authors         struct {
 		Include struct {
-			Users []string `yaml:"users"`
-			Teams []string `yaml:"teams"`
-			Orgs  []string `yaml:"orgs"`
+			Users []string         `yaml:"users"`
+			Teams []configFileTeam `yaml:"teams"`
+			Orgs  []string         `yaml:"orgs"`
 		} `yaml:"include"`
 		Exclude struct {
-			Users []string `yaml:"users"`
-			Teams []string `yaml:"teams"`
-			Orgs  []string `yaml:"orgs"`
+			Users []string         `yaml:"users"`
+			Teams []configFileTeam `yaml:"teams"`
+			Orgs  []string         `yaml:"orgs"`
 		} `yaml:"exclude"`
 	} `yaml:"authors"`
+	Reviewers struct {
+		Include struct {
+			Users []string         `yaml:"users"`
+			Teams []configFileTeam `yaml:"teams"`
+		} `yaml:"include"`
+	} `yaml:"reviewers"`
 	PullRequests struct {
 		AssignAuthor        bool     `yaml:"assignAuthor"`
 		IncludeDrafts       bool     `yaml:"includeDrafts"`
@@ -93,25 +203,85 @@ type configFile struct {
 		DeleteForAllAuthors bool     `yaml:"deleteForAllAuthors"`
 		States              []string `yaml:"states"`
 		Add                 struct {
-			States       []string `yaml:"states"`
-			AssignAuthor bool     `yaml:"assignAuthor"`
-			Drafts       bool     `yaml:"drafts"`
+			States          []string           `yaml:"states"`
+			AssignAuthor    bool               `yaml:"assignAuthor"`
+			Drafts          bool               `yaml:"drafts"`
+			Labels          configFilePatterns `yaml:"labels"`
+			BaseBranches    configFilePatterns `yaml:"baseBranches"`
+			HeadBranches    configFilePatterns `yaml:"headBranches"`
+			CreatedWithin   string             `yaml:"createdWithin"`
+			ReviewDecisions []string           `yaml:"reviewDecisions"`
+			Reviews         []string           `yaml:"reviews"`
+			ReviewRequested *bool              `yaml:"reviewRequested"`
+			Checks          []string           `yaml:"checks"`
+			Fields          map[string]string  `yaml:"fields"`
+		} `yaml:"add"`
+		Update struct {
+			Rules []struct {
+				When struct {
+					States          []string `yaml:"states"`
+					Draft           *bool    `yaml:"draft"`
+					ReviewDecisions []string `yaml:"reviewDecisions"`
+					Reviews         []string `yaml:"reviews"`
+					ReviewRequested *bool    `yaml:"reviewRequested"`
+					Checks          []string `yaml:"checks"`
+				} `yaml:"when"`
+				Set map[string]string `yaml:"set"`
+			} `yaml:"rules"`
+			AllAuthors bool `yaml:"allAuthors"`
+		} `yaml:"update"`
+		Delete struct {
+			States          []string           `yaml:"states"`
+			Drafts          bool               `yaml:"drafts"`
+			Labels          configFilePatterns `yaml:"labels"`
+			BaseBranches    configFilePatterns `yaml:"baseBranches"`
+			HeadBranches    configFilePatterns `yaml:"headBranches"`
+			StaleAfter      string             `yaml:"staleAfter"`
+			ReviewDecisions []string           `yaml:"reviewDecisions"`
+			Reviews         []string           `yaml:"reviews"`
+			ReviewRequested *bool              `yaml:"reviewRequested"`
+			Checks          []string           `yaml:"checks"`
+			AllAuthors      bool               `yaml:"allAuthors"`
+			Mode            string             `yaml:"mode"`
+		} `yaml:"delete"`
+	} `yaml:"pullRequests"`
+	Issues *struct {
+		Add struct {
+			States       []string          `yaml:"states"`
+			AssignAuthor bool              `yaml:"assignAuthor"`
+			Fields       map[string]string `yaml:"fields"`
 		} `yaml:"add"`
 		Delete struct {
 			States     []string `yaml:"states"`
-			Drafts     bool     `yaml:"drafts"`
 			AllAuthors bool     `yaml:"allAuthors"`
+			Mode       string   `yaml:"mode"`
 		} `yaml:"delete"`
-	} `yaml:"pullRequests"`
+	} `yaml:"issues"`
+}
+
+type configFile struct {
+	GitHub struct {
+		URL          string `yaml:"url"`
+		MaxAttempts  *int   `yaml:"maxAttempts"`
+		TokenCommand string `yaml:"tokenCommand"`
+		TokenTTL     string `yaml:"tokenTTL"`
+		App          *struct {
+			ID             int64  `yaml:"id"`
+			InstallationID int64  `yaml:"installationId"`
+			PrivateKeyFile string `yaml:"privateKeyFile"`
+		} `yaml:"app"`
+	}
+	// A single sync job can be defined at the top level for backward compatibility.
+	configFileJob `yaml:",inline"`
+	Jobs          []configFileJob `yaml:"jobs"`
 }
 
 func parseConfig(r io.Reader) (config, error) {
 	var (
 		cfgFile configFile
 		cfg     config
-		err     error
 	)
-	if err = yaml.NewDecoder(r).Decode(&cfgFile); err != nil {
+	if err := yaml.NewDecoder(r).Decode(&cfgFile); err != nil {
 		return config{}, err
 	}
 
@@ -124,99 +294,504 @@ func parseConfig(r io.Reader) (config, error) {
 		cfg.githubURL = configuredURL
 	}
 
-	owner, number, ok := strings.Cut(cfgFile.Project, "/")
-	if !ok || owner == "" || number == "" {
-		return config{}, fmt.Errorf("invalid project: %s", cfgFile.Project)
+	cfg.githubMaxAttempts = github.DefaultMaxAttempts
+	if cfgFile.GitHub.MaxAttempts != nil {
+		if *cfgFile.GitHub.MaxAttempts < 1 {
+			return config{}, fmt.Errorf("invalid GitHub max attempts: %d", *cfgFile.GitHub.MaxAttempts)
+		}
+		cfg.githubMaxAttempts = *cfgFile.GitHub.MaxAttempts
 	}
-	cfg.project.owner = owner
-	if cfg.project.number, err = strconv.Atoi(number); err != nil {
-		return config{}, fmt.Errorf("invalid project number: %s: %w", cfgFile.Project, err)
+
+	cfg.githubTokenCommand = strings.TrimSpace(cfgFile.GitHub.TokenCommand)
+	cfg.githubTokenTTL = defaultTokenTTL
+	if cfgFile.GitHub.TokenTTL != "" {
+		ttl, err := parseAge(cfgFile.GitHub.TokenTTL)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid GitHub tokenTTL: %w", err)
+		}
+		cfg.githubTokenTTL = ttl
 	}
 
-	for _, repo := range cfgFile.Repos {
-		owner, name, ok := strings.Cut(repo, "/")
-		if !ok || owner == "" || name == "" {
-			return config{}, fmt.Errorf("invalid repository: %s", repo)
+	if app := cfgFile.GitHub.App; app != nil {
+		switch {
+		case app.ID <= 0:
+			return config{}, fmt.Errorf("GitHub App id is required")
+		case app.InstallationID <= 0:
+			return config{}, fmt.Errorf("GitHub App installationId is required")
+		case app.PrivateKeyFile == "":
+			return config{}, fmt.Errorf("GitHub App privateKeyFile is required")
+		}
+		cfg.githubApp = &configGitHubApp{
+			id:             app.ID,
+			installationID: app.InstallationID,
+			privateKeyPath: app.PrivateKeyFile,
 		}
-		cfg.repos = append(cfg.repos, configRepo{owner, name})
 	}
-	if len(cfg.repos) == 0 {
-		return config{}, fmt.Errorf("no repositories specified")
+
+	jobs := cfgFile.Jobs
+	if len(jobs) > 0 {
+		if name := topLevelJobField(cfgFile.configFileJob); name != "" {
+			return config{}, fmt.Errorf("can't specify both a top level %s and jobs", name)
+		}
+	} else if cfgFile.Project != "" {
+		jobs = []configFileJob{cfgFile.configFileJob}
+	}
+	if len(jobs) == 0 {
+		return config{}, fmt.Errorf("no project specified")
 	}
 
-	for _, teamName := range cfgFile.Authors.Include.Teams {
-		owner, name, ok := strings.Cut(teamName, "/")
-		if !ok || owner == "" || name == "" {
-			return config{}, fmt.Errorf("invalid team: %s", teamName)
+	names := make(map[string]bool)
+	for i, jobFile := range jobs {
+		job, err := parseJob(jobFile)
+		if err != nil {
+			if jobFile.Name != "" {
+				return config{}, fmt.Errorf("job %s: %w", jobFile.Name, err)
+			}
+			if len(jobs) > 1 {
+				return config{}, fmt.Errorf("job %d: %w", i+1, err)
+			}
+			return config{}, err
+		}
+
+		if names[job.name] {
+			return config{}, fmt.Errorf("duplicate job name: %s", job.name)
+		}
+		names[job.name] = true
+
+		cfg.jobs = append(cfg.jobs, job)
+	}
+
+	return cfg, nil
+}
+
+// topLevelJobField returns the yaml name of the first set field of the top level job
+// or an empty string if none is set.
+func topLevelJobField(jobFile configFileJob) string {
+	v := reflect.ValueOf(jobFile)
+	for i := range v.NumField() {
+		if !v.Field(i).IsZero() {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			return name
 		}
-		cfg.authors.include.teams = append(cfg.authors.include.teams, configTeam{owner, name})
+	}
+	return ""
+}
+
+// parseJob parses and validates a single sync job.
+func parseJob(jobFile configFileJob) (configJob, error) {
+	var (
+		job configJob
+		err error
+	)
+
+	if job.project, err = parseProject(jobFile.Project); err != nil {
+		return configJob{}, err
+	}
+
+	job.name = jobFile.Name
+	if job.name == "" {
+		job.name = jobFile.Project
 	}
 
-	for _, teamName := range cfgFile.Authors.Exclude.Teams {
-		owner, name, ok := strings.Cut(teamName, "/")
+	for _, repo := range jobFile.Repos {
+		source, err := parseRepoSource(repo)
+		if err != nil {
+			return configJob{}, err
+		}
+		job.repoSources = append(job.repoSources, source)
+	}
+	if len(job.repoSources) == 0 {
+		return configJob{}, fmt.Errorf("no repositories specified")
+	}
+
+	for _, pattern := range jobFile.ExcludeRepos {
+		owner, name, ok := strings.Cut(pattern, "/")
 		if !ok || owner == "" || name == "" {
-			return config{}, fmt.Errorf("invalid team: %s", teamName)
+			return configJob{}, fmt.Errorf("invalid excluded repository: %s", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return configJob{}, fmt.Errorf("invalid excluded repository: %s: %w", pattern, err)
+		}
+	}
+	job.excludeRepos = jobFile.ExcludeRepos
+	job.archived = jobFile.IncludeArchived
+	job.forks = jobFile.IncludeForks
+
+	for _, teamFile := range jobFile.Authors.Include.Teams {
+		team, err := parseTeam(teamFile)
+		if err != nil {
+			return configJob{}, err
+		}
+		job.authors.include.teams = append(job.authors.include.teams, team)
+	}
+
+	for _, teamFile := range jobFile.Authors.Exclude.Teams {
+		team, err := parseTeam(teamFile)
+		if err != nil {
+			return configJob{}, err
 		}
 
-		for _, included := range cfg.authors.include.teams {
-			if included.owner == owner && included.name == name {
-				return config{}, fmt.Errorf("can't include and exclude the same team: %s", teamName)
+		for _, included := range job.authors.include.teams {
+			if included.owner == team.owner && included.name == team.name {
+				return configJob{}, fmt.Errorf("can't include and exclude the same team: %s", team)
 			}
 		}
 
-		cfg.authors.exclude.teams = append(cfg.authors.exclude.teams, configTeam{owner, name})
+		job.authors.exclude.teams = append(job.authors.exclude.teams, team)
 	}
 
-	cfg.authors.include.users = cfgFile.Authors.Include.Users
-	cfg.authors.exclude.users = cfgFile.Authors.Exclude.Users
-	for _, included := range cfg.authors.include.users {
-		for _, excluded := range cfg.authors.exclude.users {
+	job.authors.include.users = jobFile.Authors.Include.Users
+	job.authors.exclude.users = jobFile.Authors.Exclude.Users
+	for _, included := range job.authors.include.users {
+		for _, excluded := range job.authors.exclude.users {
 			if included == excluded {
-				return config{}, fmt.Errorf("can't include and exclude the same user: %s", included)
+				return configJob{}, fmt.Errorf("can't include and exclude the same user: %s", included)
 			}
 		}
 	}
 
-	cfg.authors.include.orgs = cfgFile.Authors.Include.Orgs
-	cfg.authors.exclude.orgs = cfgFile.Authors.Exclude.Orgs
-	for _, included := range cfg.authors.include.orgs {
-		for _, excluded := range cfg.authors.exclude.orgs {
+	job.authors.include.orgs = jobFile.Authors.Include.Orgs
+	job.authors.exclude.orgs = jobFile.Authors.Exclude.Orgs
+	for _, included := range job.authors.include.orgs {
+		for _, excluded := range job.authors.exclude.orgs {
 			if included == excluded {
-				return config{}, fmt.Errorf("can't include and exclude the same organization: %s", included)
+				return configJob{}, fmt.Errorf("can't include and exclude the same organization: %s", included)
 			}
 		}
 	}
 
-	cfg.pullRequests.add.assignAuthor = cfgFile.PullRequests.Add.AssignAuthor
-	cfg.pullRequests.add.drafts = cfgFile.PullRequests.Add.Drafts
+	job.reviewers.include.users = jobFile.Reviewers.Include.Users
+	for _, teamFile := range jobFile.Reviewers.Include.Teams {
+		team, err := parseTeam(teamFile)
+		if err != nil {
+			return configJob{}, fmt.Errorf("invalid reviewers: %w", err)
+		}
+		job.reviewers.include.teams = append(job.reviewers.include.teams, team)
+	}
+
+	job.pullRequests.add.assignAuthor = jobFile.PullRequests.Add.AssignAuthor
+	job.pullRequests.add.drafts = jobFile.PullRequests.Add.Drafts
 
-	cfg.pullRequests.delete.drafts = cfgFile.PullRequests.Delete.Drafts
-	cfg.pullRequests.delete.allAuthors = cfgFile.PullRequests.Delete.AllAuthors
+	for name, value := range jobFile.PullRequests.Add.Fields {
+		if name == "" || value == "" {
+			return configJob{}, fmt.Errorf("invalid pullRequest.add field: %s: %s", name, value)
+		}
+	}
+	job.pullRequests.add.fields = jobFile.PullRequests.Add.Fields
+
+	if job.pullRequests.add.labels, err = parsePatterns(jobFile.PullRequests.Add.Labels); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.add labels: %w", err)
+	}
+	if job.pullRequests.add.baseBranches, err = parsePatterns(jobFile.PullRequests.Add.BaseBranches); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.add baseBranches: %w", err)
+	}
+	if job.pullRequests.add.headBranches, err = parsePatterns(jobFile.PullRequests.Add.HeadBranches); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.add headBranches: %w", err)
+	}
+	if job.pullRequests.add.createdWithin, err = parseAge(jobFile.PullRequests.Add.CreatedWithin); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.add createdWithin: %w", err)
+	}
+
+	job.pullRequests.delete.drafts = jobFile.PullRequests.Delete.Drafts
+	if job.pullRequests.delete.labels, err = parsePatterns(jobFile.PullRequests.Delete.Labels); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.delete labels: %w", err)
+	}
+	if job.pullRequests.delete.baseBranches, err = parsePatterns(jobFile.PullRequests.Delete.BaseBranches); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.delete baseBranches: %w", err)
+	}
+	if job.pullRequests.delete.headBranches, err = parsePatterns(jobFile.PullRequests.Delete.HeadBranches); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.delete headBranches: %w", err)
+	}
+	if job.pullRequests.delete.staleAfter, err = parseAge(jobFile.PullRequests.Delete.StaleAfter); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.delete staleAfter: %w", err)
+	}
+	job.pullRequests.delete.allAuthors = jobFile.PullRequests.Delete.AllAuthors
 
-	for _, state := range cfgFile.PullRequests.Add.States {
+	if job.pullRequests.delete.mode, err = parseDeleteMode(jobFile.PullRequests.Delete.Mode); err != nil {
+		return configJob{}, fmt.Errorf("invalid pullRequest.delete mode: %w", err)
+	}
+
+	for _, state := range jobFile.PullRequests.Add.States {
 		prState := github.PullRequestState(strings.ToUpper(state))
 		if !prState.IsValid() {
-			return config{}, fmt.Errorf("invalid pullRequest.add state: %s", state)
+			return configJob{}, fmt.Errorf("invalid pullRequest.add state: %s", state)
 		}
-		cfg.pullRequests.add.states = append(cfg.pullRequests.add.states, prState)
+		job.pullRequests.add.states = append(job.pullRequests.add.states, prState)
+	}
+	if len(job.pullRequests.add.states) == 0 {
+		// By default, add pull requests in OPEN state.
+		job.pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
 	}
-	for _, state := range cfgFile.PullRequests.Delete.States {
+	for _, state := range jobFile.PullRequests.Delete.States {
 		prState := github.PullRequestState(strings.ToUpper(state))
 		if !prState.IsValid() {
-			return config{}, fmt.Errorf("invalid pullRequest.delete state: %s", state)
+			return configJob{}, fmt.Errorf("invalid pullRequest.delete state: %s", state)
 		}
-		for _, addState := range cfg.pullRequests.add.states {
+		for _, addState := range job.pullRequests.add.states {
 			if prState == addState {
-				return config{}, fmt.Errorf("can't add and delete pull requests in %s state", state)
+				return configJob{}, fmt.Errorf("can't add and delete pull requests in %s state", state)
 			}
 		}
-		cfg.pullRequests.delete.states = append(cfg.pullRequests.delete.states, prState)
+		job.pullRequests.delete.states = append(job.pullRequests.delete.states, prState)
 	}
 
-	if len(cfgFile.PullRequests.States) == 0 {
-		// By default, add pull requests in OPEN state.
-		cfg.pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
+	for _, decision := range jobFile.PullRequests.Add.ReviewDecisions {
+		reviewDecision := github.ReviewDecision(strings.ToUpper(decision))
+		if !reviewDecision.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.add review decision: %s", decision)
+		}
+		job.pullRequests.add.reviewDecisions = append(job.pullRequests.add.reviewDecisions, reviewDecision)
+	}
+	for _, decision := range jobFile.PullRequests.Delete.ReviewDecisions {
+		reviewDecision := github.ReviewDecision(strings.ToUpper(decision))
+		if !reviewDecision.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.delete review decision: %s", decision)
+		}
+		if slices.Contains(job.pullRequests.add.reviewDecisions, reviewDecision) {
+			return configJob{}, fmt.Errorf("can't add and delete pull requests with %s review decision", decision)
+		}
+		job.pullRequests.delete.reviewDecisions = append(job.pullRequests.delete.reviewDecisions, reviewDecision)
 	}
 
-	return cfg, nil
+	for _, state := range jobFile.PullRequests.Add.Reviews {
+		reviewState := github.ReviewState(strings.ToUpper(state))
+		if !reviewState.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.add review: %s", state)
+		}
+		job.pullRequests.add.reviews = append(job.pullRequests.add.reviews, reviewState)
+	}
+	for _, state := range jobFile.PullRequests.Delete.Reviews {
+		reviewState := github.ReviewState(strings.ToUpper(state))
+		if !reviewState.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.delete review: %s", state)
+		}
+		if slices.Contains(job.pullRequests.add.reviews, reviewState) {
+			return configJob{}, fmt.Errorf("can't add and delete pull requests with %s reviews", state)
+		}
+		job.pullRequests.delete.reviews = append(job.pullRequests.delete.reviews, reviewState)
+	}
+
+	job.pullRequests.add.reviewRequested = jobFile.PullRequests.Add.ReviewRequested
+	job.pullRequests.delete.reviewRequested = jobFile.PullRequests.Delete.ReviewRequested
+	if add, del := job.pullRequests.add.reviewRequested, job.pullRequests.delete.reviewRequested; add != nil && del != nil && *add == *del {
+		return configJob{}, fmt.Errorf("can't add and delete pull requests with reviewRequested %t", *add)
+	}
+
+	for _, state := range jobFile.PullRequests.Add.Checks {
+		checkState := github.CheckState(strings.ToUpper(state))
+		if !checkState.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.add checks: %s", state)
+		}
+		job.pullRequests.add.checks = append(job.pullRequests.add.checks, checkState)
+	}
+	for _, state := range jobFile.PullRequests.Delete.Checks {
+		checkState := github.CheckState(strings.ToUpper(state))
+		if !checkState.IsValid() {
+			return configJob{}, fmt.Errorf("invalid pullRequest.delete checks: %s", state)
+		}
+		if slices.Contains(job.pullRequests.add.checks, checkState) {
+			return configJob{}, fmt.Errorf("can't add and delete pull requests with %s checks", state)
+		}
+		job.pullRequests.delete.checks = append(job.pullRequests.delete.checks, checkState)
+	}
+
+	job.pullRequests.update.allAuthors = jobFile.PullRequests.Update.AllAuthors
+	for i, ruleFile := range jobFile.PullRequests.Update.Rules {
+		var rule configUpdateRule
+
+		for _, state := range ruleFile.When.States {
+			prState := github.PullRequestState(strings.ToUpper(state))
+			if !prState.IsValid() {
+				return configJob{}, fmt.Errorf("invalid pullRequest.update rule %d state: %s", i+1, state)
+			}
+			rule.when.states = append(rule.when.states, prState)
+		}
+		rule.when.draft = ruleFile.When.Draft
+		for _, decision := range ruleFile.When.ReviewDecisions {
+			reviewDecision := github.ReviewDecision(strings.ToUpper(decision))
+			if !reviewDecision.IsValid() {
+				return configJob{}, fmt.Errorf("invalid pullRequest.update rule %d review decision: %s", i+1, decision)
+			}
+			rule.when.reviewDecisions = append(rule.when.reviewDecisions, reviewDecision)
+		}
+		for _, state := range ruleFile.When.Reviews {
+			reviewState := github.ReviewState(strings.ToUpper(state))
+			if !reviewState.IsValid() {
+				return configJob{}, fmt.Errorf("invalid pullRequest.update rule %d review: %s", i+1, state)
+			}
+			rule.when.reviews = append(rule.when.reviews, reviewState)
+		}
+		rule.when.reviewRequested = ruleFile.When.ReviewRequested
+		for _, state := range ruleFile.When.Checks {
+			checkState := github.CheckState(strings.ToUpper(state))
+			if !checkState.IsValid() {
+				return configJob{}, fmt.Errorf("invalid pullRequest.update rule %d checks: %s", i+1, state)
+			}
+			rule.when.checks = append(rule.when.checks, checkState)
+		}
+		if rule.when.empty() {
+			return configJob{}, fmt.Errorf("pullRequest.update rule %d has no conditions", i+1)
+		}
+
+		if len(ruleFile.Set) == 0 {
+			return configJob{}, fmt.Errorf("pullRequest.update rule %d has no fields to set", i+1)
+		}
+		for name, value := range ruleFile.Set {
+			if name == "" || value == "" {
+				return configJob{}, fmt.Errorf("invalid pullRequest.update rule %d field: %s: %s", i+1, name, value)
+			}
+		}
+		rule.set = ruleFile.Set
+
+		job.pullRequests.update.rules = append(job.pullRequests.update.rules, rule)
+	}
+
+	if jobFile.Issues != nil {
+		job.issues.enabled = true
+		job.issues.add.assignAuthor = jobFile.Issues.Add.AssignAuthor
+		job.issues.delete.allAuthors = jobFile.Issues.Delete.AllAuthors
+
+		for name, value := range jobFile.Issues.Add.Fields {
+			if name == "" || value == "" {
+				return configJob{}, fmt.Errorf("invalid issues.add field: %s: %s", name, value)
+			}
+		}
+		job.issues.add.fields = jobFile.Issues.Add.Fields
+
+		if job.issues.delete.mode, err = parseDeleteMode(jobFile.Issues.Delete.Mode); err != nil {
+			return configJob{}, fmt.Errorf("invalid issues.delete mode: %w", err)
+		}
+
+		for _, state := range jobFile.Issues.Add.States {
+			issueState := github.IssueState(strings.ToUpper(state))
+			if !issueState.IsValid() {
+				return configJob{}, fmt.Errorf("invalid issues.add state: %s", state)
+			}
+			job.issues.add.states = append(job.issues.add.states, issueState)
+		}
+		if len(job.issues.add.states) == 0 {
+			// By default, add issues in OPEN state.
+			job.issues.add.states = []github.IssueState{github.IssueStateOpen}
+		}
+
+		for _, state := range jobFile.Issues.Delete.States {
+			issueState := github.IssueState(strings.ToUpper(state))
+			if !issueState.IsValid() {
+				return configJob{}, fmt.Errorf("invalid issues.delete state: %s", state)
+			}
+			for _, addState := range job.issues.add.states {
+				if issueState == addState {
+					return configJob{}, fmt.Errorf("can't add and delete issues in %s state", state)
+				}
+			}
+			job.issues.delete.states = append(job.issues.delete.states, issueState)
+		}
+	}
+
+	return job, nil
+}
+
+func parseDeleteMode(mode string) (deleteMode, error) {
+	switch m := deleteMode(strings.ToLower(mode)); m {
+	case "":
+		return deleteModeDelete, nil
+	case deleteModeDelete, deleteModeArchive:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%s", mode)
+	}
+}
+
+// parseTeam parses a team in the <owner>/<name> form and its membership.
+func parseTeam(team configFileTeam) (configTeam, error) {
+	owner, name, ok := strings.Cut(team.Name, "/")
+	if !ok || owner == "" || name == "" {
+		return configTeam{}, fmt.Errorf("invalid team: %s", team.Name)
+	}
+
+	membership := github.TeamMembership(strings.ToUpper(team.Membership))
+	if membership != "" && !membership.IsValid() {
+		return configTeam{}, fmt.Errorf("invalid team membership: %s: %s", team.Name, team.Membership)
+	}
+
+	return configTeam{owner: owner, name: name, membership: membership}, nil
+}
+
+// parsePatterns validates include and exclude glob patterns.
+func parsePatterns(patterns configFilePatterns) (configPatterns, error) {
+	for _, pattern := range slices.Concat(patterns.Include, patterns.Exclude) {
+		if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+			return configPatterns{}, fmt.Errorf("invalid pattern: %q", pattern)
+		}
+	}
+	for _, included := range patterns.Include {
+		if slices.Contains(patterns.Exclude, included) {
+			return configPatterns{}, fmt.Errorf("can't include and exclude the same pattern: %s", included)
+		}
+	}
+
+	return configPatterns{include: patterns.Include, exclude: patterns.Exclude}, nil
+}
+
+// parseAge parses a duration with an optional number of days or weeks, e.g. 30d, 2w or 36h.
+// An empty value means no limit.
+func parseAge(age string) (time.Duration, error) {
+	if age == "" {
+		return 0, nil
+	}
+
+	var (
+		d   time.Duration
+		err error
+	)
+	if number, unit := age[:len(age)-1], age[len(age)-1]; unit == 'd' || unit == 'w' {
+		var n int
+		if n, err = strconv.Atoi(number); err == nil {
+			d = time.Duration(n) * 24 * time.Hour
+			if unit == 'w' {
+				d *= 7
+			}
+		}
+	} else {
+		d, err = time.ParseDuration(age)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s", age)
+	}
+
+	return d, nil
+}
+
+// parseProject parses a project reference in one of the following forms:
+//   - <owner>/<number> the owner is resolved as either an organization or a user
+//   - orgs/<owner>/<number> the owner is an organization
+//   - users/<owner>/<number> the owner is a user
+func parseProject(project string) (configProject, error) {
+	var (
+		prj configProject
+		err error
+	)
+
+	parts := strings.Split(project, "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "orgs":
+		prj.ownerType = github.ProjectOwnerTypeOrganization
+		parts = parts[1:]
+	case len(parts) == 3 && parts[0] == "users":
+		prj.ownerType = github.ProjectOwnerTypeUser
+		parts = parts[1:]
+	case len(parts) != 2:
+		return configProject{}, fmt.Errorf("invalid project: %s", project)
+	}
+
+	if parts[0] == "" || parts[1] == "" {
+		return configProject{}, fmt.Errorf("invalid project: %s", project)
+	}
+
+	prj.owner = parts[0]
+	if prj.number, err = strconv.Atoi(parts[1]); err != nil {
+		return configProject{}, fmt.Errorf("invalid project number: %s: %w", project, err)
+	}
+
+	return prj, nil
 }
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestParseProject(t *testing.T) {
+	tests := []struct {
+		project string
+		want    configProject
+		wantErr bool
+	}{
+		{project: "org/1", want: configProject{owner: "org", number: 1}},
+		{project: "orgs/org/2", want: configProject{owner: "org", ownerType: github.ProjectOwnerTypeOrganization, number: 2}},
+		{project: "users/user/3", want: configProject{owner: "user", ownerType: github.ProjectOwnerTypeUser, number: 3}},
+		{project: "", wantErr: true},
+		{project: "org", wantErr: true},
+		{project: "org/", wantErr: true},
+		{project: "/1", wantErr: true},
+		{project: "org/one", wantErr: true},
+		{project: "teams/org/1", wantErr: true},
+		{project: "users//1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.project, func(t *testing.T) {
+			got, err := parseProject(tt.project)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want; want != got {
+				t.Fatalf("Expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestParseConfigJobs(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+jobs:
+  - name: platform
+    project: org/1
+    repos:
+      - org/repo1
+  - project: users/user/2
+    repos:
+      - org/repo2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 2, len(cfg.jobs); want != got {
+		t.Fatalf("Expected %d jobs, got %d", want, got)
+	}
+	if want, got := "platform", cfg.jobs[0].name; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "users/user/2", cfg.jobs[1].name; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := (configRepoSource{owner: "org", name: "repo2"}), cfg.jobs[1].repoSources[0]; want != got {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseConfigSingleJob(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos:
+  - org/repo1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 1, len(cfg.jobs); want != got {
+		t.Fatalf("Expected %d jobs, got %d", want, got)
+	}
+	if want, got := (configProject{owner: "org", number: 1}), cfg.jobs[0].project; want != got {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	if want, got := github.DefaultMaxAttempts, cfg.githubMaxAttempts; want != got {
+		t.Fatalf("Expected %d, got %d", want, got)
+	}
+}
+
+func TestParseConfigPullRequestStates(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []github.PullRequestState{github.PullRequestStateOpen}, cfg.jobs[0].pullRequests.add.states; !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	cfg, err = parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  add:
+    states: [open, merged]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []github.PullRequestState{github.PullRequestStateOpen, github.PullRequestStateMerged}, cfg.jobs[0].pullRequests.add.states; !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	// The default add state can't be deleted either.
+	if _, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  delete:
+    states: [open]
+`)); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestParseConfigMaxAttempts(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+github:
+  maxAttempts: 5
+project: org/1
+repos: [org/repo1]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 5, cfg.githubMaxAttempts; want != got {
+		t.Fatalf("Expected %d, got %d", want, got)
+	}
+
+	if _, err := parseConfig(strings.NewReader(`
+github:
+  maxAttempts: 0
+project: org/1
+repos: [org/repo1]
+`)); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestParseConfigGitHubApp(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+github:
+  app:
+    id: 123
+    installationId: 456
+    privateKeyFile: app.pem
+project: org/1
+repos: [org/repo1]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.githubApp == nil {
+		t.Fatal("Expected a GitHub App")
+	}
+	if want, got := (configGitHubApp{id: 123, installationID: 456, privateKeyPath: "app.pem"}), *cfg.githubApp; want != got {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	if _, err := parseConfig(strings.NewReader(`
+github:
+  app:
+    id: 123
+    privateKeyFile: app.pem
+project: org/1
+repos: [org/repo1]
+`)); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestParseConfigJobsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "project and jobs",
+			config: `
+project: org/1
+repos: [org/repo1]
+jobs:
+  - project: org/2
+    repos: [org/repo2]
+`,
+		},
+		{
+			name: "repos and jobs",
+			config: `
+repos: [org/repo1]
+jobs:
+  - project: org/2
+    repos: [org/repo2]
+`,
+		},
+		{
+			name: "pull requests and jobs",
+			config: `
+pullRequests:
+  add:
+    states: [merged]
+jobs:
+  - project: org/2
+    repos: [org/repo2]
+`,
+		},
+		{
+			name: "issues and jobs",
+			config: `
+issues: {}
+jobs:
+  - project: org/2
+    repos: [org/repo2]
+`,
+		},
+		{
+			name:   "no project",
+			config: `repos: [org/repo1]`,
+		},
+		{
+			name: "duplicate name",
+			config: `
+jobs:
+  - name: job
+    project: org/1
+    repos: [org/repo1]
+  - name: job
+    project: org/2
+    repos: [org/repo2]
+`,
+		},
+		{
+			name: "invalid job",
+			config: `
+jobs:
+  - project: org/1
+    repos: [org/repo1]
+  - project: org/2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseConfig(strings.NewReader(tt.config)); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseConfigUpdateRules(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  update:
+    rules:
+      - when:
+          draft: true
+        set:
+          Status: In Progress
+      - when:
+          reviewDecisions: [approved]
+          states: [open]
+        set:
+          Status: Ready to merge
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := cfg.jobs[0].pullRequests.update.rules
+	if want, got := 2, len(rules); want != got {
+		t.Fatalf("Expected %d rules, got %d", want, got)
+	}
+	if rules[0].when.draft == nil || !*rules[0].when.draft {
+		t.Fatal("Expected draft condition")
+	}
+	if want, got := github.ReviewDecisionApproved, rules[1].when.reviewDecisions[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "Ready to merge", rules[1].set["Status"]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+
+	for _, config := range []string{
+		"pullRequests: {update: {rules: [{set: {Status: Done}}]}}",
+		"pullRequests: {update: {rules: [{when: {states: [MERGED]}}]}}",
+		"pullRequests: {update: {rules: [{when: {states: [UNKNOWN]}, set: {Status: Done}}]}}",
+		"pullRequests: {update: {rules: [{when: {reviewDecisions: [UNKNOWN]}, set: {Status: Done}}]}}",
+	} {
+		_, err := parseConfig(strings.NewReader("project: org/1\nrepos: [org/repo1]\n" + config))
+		if err == nil {
+			t.Fatalf("Expected an error for %s", config)
+		}
+	}
+}
+
+func TestParseConfigPatterns(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  add:
+    labels:
+      include: [needs-design-review]
+      exclude: [dependencies]
+  delete:
+    labels:
+      exclude: [wontfix, "do-not-track/*"]
+    baseBranches:
+      include: [main, "release/*"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := cfg.jobs[0]
+	if want, got := "needs-design-review", job.pullRequests.add.labels.include[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "dependencies", job.pullRequests.add.labels.exclude[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 2, len(job.pullRequests.delete.labels.exclude); want != got {
+		t.Fatalf("Expected %d delete labels, got %d", want, got)
+	}
+	if want, got := "release/*", job.pullRequests.delete.baseBranches.include[1]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+
+	for _, config := range []string{
+		"pullRequests: {add: {labels: {include: [bug], exclude: [bug]}}}",
+		"pullRequests: {add: {labels: {include: ['']}}}",
+		"pullRequests: {delete: {labels: {exclude: ['[']}}}",
+		"pullRequests: {add: {baseBranches: {include: ['release/[']}}}",
+		"pullRequests: {delete: {headBranches: {exclude: ['']}}}",
+	} {
+		_, err := parseConfig(strings.NewReader("project: org/1\nrepos: [org/repo1]\n" + config))
+		if err == nil {
+			t.Fatalf("Expected an error for %s", config)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age  string
+		want time.Duration
+	}{
+		{"", 0},
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAge(tt.age)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.want, got; want != got {
+			t.Fatalf("Expected %s for %s, got %s", want, tt.age, got)
+		}
+	}
+
+	for _, age := range []string{"d", "30", "-1d", "0d", "1y", "abc"} {
+		if _, err := parseAge(age); err == nil {
+			t.Fatalf("Expected an error for %s", age)
+		}
+	}
+}
+
+func TestParseConfigReviewRules(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  add:
+    reviewDecisions: [review_required]
+    reviews: [commented]
+    reviewRequested: true
+  update:
+    rules:
+      - when:
+          reviews: [changes_requested]
+          reviewRequested: false
+        set:
+          Status: Changes requested
+  delete:
+    reviewDecisions: [APPROVED]
+    reviews: [approved]
+    reviewRequested: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := cfg.jobs[0]
+	if want, got := github.ReviewDecisionReviewRequired, job.pullRequests.add.reviewDecisions[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := github.ReviewDecisionApproved, job.pullRequests.delete.reviewDecisions[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := github.ReviewStateCommented, job.pullRequests.add.reviews[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := github.ReviewStateApproved, job.pullRequests.delete.reviews[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if job.pullRequests.add.reviewRequested == nil || !*job.pullRequests.add.reviewRequested {
+		t.Fatal("Expected add reviewRequested true condition")
+	}
+	if job.pullRequests.delete.reviewRequested == nil || *job.pullRequests.delete.reviewRequested {
+		t.Fatal("Expected delete reviewRequested false condition")
+	}
+	if !deletesPullRequests(job) {
+		t.Fatal("Expected the job to delete pull requests")
+	}
+	when := job.pullRequests.update.rules[0].when
+	if want, got := github.ReviewStateChangesRequested, when.reviews[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if when.reviewRequested == nil || *when.reviewRequested {
+		t.Fatal("Expected reviewRequested false condition")
+	}
+
+	for _, config := range []string{
+		"pullRequests: {add: {reviewDecisions: [UNKNOWN]}}",
+		"pullRequests: {delete: {reviewDecisions: [UNKNOWN]}}",
+		"pullRequests: {add: {reviewDecisions: [APPROVED]}, delete: {reviewDecisions: [APPROVED]}}",
+		"pullRequests: {update: {rules: [{when: {reviews: [UNKNOWN]}, set: {Status: Done}}]}}",
+		"pullRequests: {add: {reviews: [UNKNOWN]}}",
+		"pullRequests: {delete: {reviews: [UNKNOWN]}}",
+		"pullRequests: {add: {reviews: [APPROVED]}, delete: {reviews: [APPROVED]}}",
+		"pullRequests: {add: {reviewRequested: true}, delete: {reviewRequested: true}}",
+	} {
+		_, err := parseConfig(strings.NewReader("project: org/1\nrepos: [org/repo1]\n" + config))
+		if err == nil {
+			t.Fatalf("Expected an error for %s", config)
+		}
+	}
+}
+
+func TestParseConfigChecks(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+pullRequests:
+  add:
+    checks: [success, pending]
+  update:
+    rules:
+      - when:
+          checks: [FAILURE, ERROR]
+        set:
+          Status: Failing
+  delete:
+    checks: [failure]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := cfg.jobs[0]
+	if want, got := 2, len(job.pullRequests.add.checks); want != got {
+		t.Fatalf("Expected %d add checks, got %d", want, got)
+	}
+	if want, got := github.CheckStateFailure, job.pullRequests.delete.checks[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := github.CheckStateError, job.pullRequests.update.rules[0].when.checks[1]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+
+	for _, config := range []string{
+		"pullRequests: {add: {checks: [UNKNOWN]}}",
+		"pullRequests: {delete: {checks: [UNKNOWN]}}",
+		"pullRequests: {add: {checks: [SUCCESS]}, delete: {checks: [SUCCESS]}}",
+		"pullRequests: {update: {rules: [{when: {checks: [UNKNOWN]}, set: {Status: Done}}]}}",
+	} {
+		_, err := parseConfig(strings.NewReader("project: org/1\nrepos: [org/repo1]\n" + config))
+		if err == nil {
+			t.Fatalf("Expected an error for %s", config)
+		}
+	}
+}
+
+func TestParseConfigTeams(t *testing.T) {
+	cfg, err := parseConfig(strings.NewReader(`
+project: org/1
+repos: [org/repo1]
+authors:
+  include:
+    teams:
+      - org/team1
+      - name: org/team2
+        membership: immediate
+  exclude:
+    teams:
+      - name: org/team3
+reviewers:
+  include:
+    teams:
+      - name: org/team4
+        membership: ALL
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job := cfg.jobs[0]
+	for _, tt := range []struct {
+		want configTeam
+		got  configTeam
+	}{
+		{configTeam{owner: "org", name: "team1"}, job.authors.include.teams[0]},
+		{configTeam{owner: "org", name: "team2", membership: github.TeamMembershipImmediate}, job.authors.include.teams[1]},
+		{configTeam{owner: "org", name: "team3"}, job.authors.exclude.teams[0]},
+		{configTeam{owner: "org", name: "team4", membership: github.TeamMembershipAll}, job.reviewers.include.teams[0]},
+	} {
+		if tt.want != tt.got {
+			t.Fatalf("Expected %+v, got %+v", tt.want, tt.got)
+		}
+	}
+
+	for _, config := range []string{
+		"authors: {include: {teams: [{name: org/team1, membership: CHILD}]}}",
+		"authors: {include: {teams: [{membership: ALL}]}}",
+		"authors: {include: {teams: [org/team1]}, exclude: {teams: [{name: org/team1, membership: IMMEDIATE}]}}",
+		"reviewers: {include: {teams: [team1]}}",
+	} {
+		_, err := parseConfig(strings.NewReader("project: org/1\nrepos: [org/repo1]\n" + config))
+		if err == nil {
+			t.Fatalf("Expected an error for %s", config)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// explain prints how the rules of every job apply to an author or a pull request.
+func explain(ctx context.Context, args []string) error {
+	var (
+		configPath string
+		tokenFile  string
+		verbose    bool
+	)
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", "config.yaml", "Path to the config file")
+	flags.StringVar(&tokenFile, "token-file", "", "Path to a file with the GitHub token")
+	flags.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: prsync explain [flags] <login>|<pull request URL>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected a login or a pull request URL")
+	}
+
+	// Nothing is changed while explaining.
+	cfg, client, err := setup(ctx, os.Stdout, configPath, tokenFile, true, verbose)
+	if err != nil {
+		return err
+	}
+	memberships := newMemberships(client, cfg.verbose)
+
+	arg := flags.Arg(0)
+	if !strings.Contains(arg, "/") {
+		return explainAuthor(ctx, os.Stdout, cfg, memberships, arg)
+	}
+
+	owner, name, number, err := parsePullRequestURL(arg)
+	if err != nil {
+		return err
+	}
+
+	return explainPullRequest(ctx, os.Stdout, client, cfg, memberships, owner, name, number)
+}
+
+// parsePullRequestURL parses a https://<host>/<owner>/<name>/pull/<number> URL.
+func parsePullRequestURL(rawURL string) (string, string, int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid pull request URL: %s: %w", rawURL, err)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] == "" || parts[1] == "" || parts[2] != "pull" {
+		return "", "", 0, fmt.Errorf("invalid pull request URL: %s", rawURL)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("invalid pull request number: %s", rawURL)
+	}
+
+	return parts[0], parts[1], number, nil
+}
+
+// explainAuthor writes the author resolution trail of every job.
+func explainAuthor(ctx context.Context, w io.Writer, cfg config, memberships *memberships, login string) error {
+	for _, job := range cfg.jobs {
+		authors, err := NewAuthors(ctx, memberships, job.authors)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+
+		included, trail, err := authors.Explain(ctx, login)
+		if err != nil {
+			return fmt.Errorf("job %s: error resolving %s: %w", job.name, login, err)
+		}
+
+		fmt.Fprintf(w, "Job: %s\n", job.name)
+		for _, step := range trail {
+			fmt.Fprintf(w, "  - %s\n", step)
+		}
+		if included {
+			fmt.Fprintf(w, "  %s is INCLUDED\n", login)
+		} else {
+			fmt.Fprintf(w, "  %s is EXCLUDED\n", login)
+		}
+	}
+
+	return nil
+}
+
+// explainPullRequest writes the add and delete rules of every job with their results
+// and the action a sync would take on the pull request.
+// Jobs that don't include the pull request's repository skip it.
+func explainPullRequest(
+	ctx context.Context,
+	w io.Writer,
+	client githubClient,
+	cfg config,
+	memberships *memberships,
+	owner, name string,
+	number int,
+) error {
+	pr, err := client.GetPullRequest(ctx, owner, name, number)
+	if err != nil {
+		return fmt.Errorf("error fetching pull request %s/%s#%d: %w", owner, name, number, err)
+	}
+
+	fmt.Fprintf(w, "Pull request: %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+
+	repo, err := client.GetRepository(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("error fetching repository %s/%s: %w", owner, name, err)
+	}
+
+	// A real sync never makes changes while explaining.
+	cfg.dryRun = true
+	now := time.Now()
+	for _, job := range cfg.jobs {
+		fmt.Fprintf(w, "Job: %s\n", job.name)
+
+		included, err := includesRepo(ctx, client, job, repo, repo.TopicNames())
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+		fmt.Fprintf(w, "  Repository %s/%s: %s\n", repo.Owner.Login, repo.Name, passFail(included))
+		if !included {
+			fmt.Fprintf(w, "  Action: %s (repository not included)\n", strings.ToUpper(decisionSkip))
+			continue
+		}
+
+		authors, err := NewAuthors(ctx, memberships, job.authors)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+		reviewers, err := NewReviewers(ctx, memberships, job.reviewers)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+		project, err := client.GetProject(ctx, job.project.owner, job.project.ownerType, job.project.number)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+		fields, err := resolveJobFields(ctx, client, job, project)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+
+		item := pr.FindProjectItem(project.ID)
+		switch {
+		case item == nil:
+			fmt.Fprintf(w, "  Project: %d %s (not in the project)\n", project.Number, project.Title)
+		case item.IsArchived:
+			fmt.Fprintf(w, "  Project: %d %s (archived)\n", project.Number, project.Title)
+		default:
+			fmt.Fprintf(w, "  Project: %d %s (in the project)\n", project.Number, project.Title)
+		}
+
+		ourAuthor, trail, err := authors.Explain(ctx, pr.Author.Login)
+		if err != nil {
+			return fmt.Errorf("job %s: error resolving %s: %w", job.name, pr.Author.Login, err)
+		}
+		fmt.Fprintf(w, "  Author %s: %s\n", pr.Author.Login, passFail(ourAuthor))
+		for _, step := range trail {
+			fmt.Fprintf(w, "    - %s\n", step)
+		}
+
+		fmt.Fprintln(w, "  Add rules:")
+		for _, rule := range addRules(job, now) {
+			fmt.Fprintf(w, "    - %s %s\n", passFail(rule.matches(pr)), rule.name)
+		}
+		if deletesPullRequests(job) {
+			fmt.Fprintf(w, "    - %s no delete rule matches\n", passFail(!isDeleteCandidate(job, pr, now)))
+		}
+		fmt.Fprintf(w, "    - %s author is included\n", passFail(ourAuthor))
+		ourReviewer, err := reviewers.Resolve(ctx, pr)
+		if err != nil {
+			return fmt.Errorf("job %s: error evaluating reviewer filter for %s: %w", job.name, pr.URL, err)
+		}
+		fmt.Fprintf(w, "    - %s reviewers are included\n", passFail(ourReviewer))
+
+		if deletesPullRequests(job) {
+			fmt.Fprintln(w, "  Delete rules (any):")
+			for _, rule := range deleteRules(job, now) {
+				fmt.Fprintf(w, "    - %s %s\n", passFail(rule.matches(pr)), rule.name)
+			}
+			if job.pullRequests.delete.allAuthors {
+				fmt.Fprintln(w, "    Applied to all authors")
+			} else {
+				fmt.Fprintf(w, "    Applied to included authors: %s\n", passFail(ourAuthor))
+			}
+		}
+
+		if item != nil && len(fields.updateRules) > 0 {
+			for i, rule := range fields.updateRules {
+				if rule.when.matches(pr) {
+					fmt.Fprintf(w, "  Update rule %d matches\n", i+1)
+					break
+				}
+			}
+		}
+
+		decision, err := decidePullRequest(ctx, client, cfg, job, authors, reviewers, project, pr, fields)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.name, err)
+		}
+		fmt.Fprintf(w, "  Action: %s\n", strings.ToUpper(decision))
+	}
+
+	return nil
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "PASS"
+	}
+	return "FAIL"
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestAuthorsExplain(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			if name == "bots" {
+				return seqOf(github.User{ID: "bot", Login: "bot"})
+			}
+			return seqOf(github.User{ID: "member", Login: "member"})
+		},
+		GetUserOrganizationsFunc: func(ctx context.Context, login string) iter.Seq2[*github.Organization, error] {
+			if login == "public" {
+				return seqOf(github.Organization{Login: "org1"})
+			}
+			return seqOf[github.Organization]()
+		},
+		IsOrganizationMemberFunc: func(ctx context.Context, login, org string) (bool, error) {
+			return login == "private" && org == "org1", nil
+		},
+	}
+	rules := configAuthors{
+		include: configAuthorRules{
+			users: []string{"user"},
+			teams: []configTeam{{owner: "org1", name: "team1"}},
+			orgs:  []string{"org1"},
+		},
+		exclude: configAuthorRules{
+			teams: []configTeam{{owner: "org1", name: "bots"}},
+		},
+	}
+
+	tests := []struct {
+		login    string
+		included bool
+		step     string
+	}{
+		{"user", true, "Explicitly included by the users rule"},
+		{"bot", false, "Excluded as a member of team org1/bots"},
+		{"member", true, "Included as a member of team org1/team1"},
+		{"public", true, "Included as a member of organization org1 (public organizations)"},
+		{"private", true, "Included as a member of organization org1 (organization membership check)"},
+		{"outsider", false, "Not matched by any include rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.login, func(t *testing.T) {
+			authors, err := NewAuthors(ctx, newMemberships(client, false), rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			included, trail, err := authors.Explain(ctx, tt.login)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.included, included; want != got {
+				t.Fatalf("Expected %t, got %t", want, got)
+			}
+			if !slices.Contains(trail, tt.step) {
+				t.Fatalf("Expected %q in %q", tt.step, trail)
+			}
+
+			resolved, err := authors.Resolve(ctx, tt.login)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := included, resolved; want != got {
+				t.Fatalf("Expected %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestExplainAuthor(t *testing.T) {
+	cfg := config{jobs: []configJob{{name: "job1"}}}
+
+	var out strings.Builder
+	if err := explainAuthor(context.Background(), &out, cfg, newMemberships(&fakeGithubClient{}, false), "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Job: job1\n  - No author rules, all authors are included\n  user is INCLUDED\n"
+	if got := out.String(); want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestParsePullRequestURL(t *testing.T) {
+	owner, name, number, err := parsePullRequestURL("https://github.com/org/repo/pull/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "org" || name != "repo" || number != 42 {
+		t.Fatalf("Expected org/repo#42, got %s/%s#%d", owner, name, number)
+	}
+
+	for _, url := range []string{
+		"https://github.com/org/repo",
+		"https://github.com/org/repo/issues/42",
+		"https://github.com/org/repo/pull/abc",
+		"https://github.com/org/repo/pull/0",
+	} {
+		if _, _, _, err := parsePullRequestURL(url); err == nil {
+			t.Fatalf("Expected an error for %s", url)
+		}
+	}
+}
+
+func TestExplainPullRequest(t *testing.T) {
+	ctx := context.Background()
+	project := &github.Project{ID: "project", Number: 1, Title: "Board"}
+
+	pr := &github.PullRequest{ID: "PR", URL: "https://github.com/org/repo/pull/1", State: github.PullRequestStateOpen, IsDraft: true}
+	pr.Author.Type = github.AuthorTypeUser
+	pr.Author.Login = "user"
+
+	client := &fakeGithubClient{
+		GetPullRequestFunc: func(ctx context.Context, owner, name string, number int) (*github.PullRequest, error) {
+			return pr, nil
+		},
+		GetProjectFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
+			return project, nil
+		},
+		GetRepositoryFunc: func(ctx context.Context, owner, name string) (*github.Repository, error) {
+			repo := &github.Repository{Name: name}
+			repo.Owner.Login = owner
+			return repo, nil
+		},
+		AddPullRequestToProjectFunc: func(ctx context.Context, projectID, prID string) (string, error) {
+			t.Fatal("Unexpected call to AddPullRequestToProject")
+			return "", nil
+		},
+	}
+
+	job := configJob{name: "job1", repoSources: []configRepoSource{{kind: repoSourceName, owner: "org", name: "repo"}}}
+	job.pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
+	job.pullRequests.delete.states = []github.PullRequestState{github.PullRequestStateMerged}
+	cfg := config{jobs: []configJob{job}}
+
+	var out strings.Builder
+	if err := explainPullRequest(ctx, &out, client, cfg, newMemberships(client, false), "org", "repo", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"  Project: 1 Board (not in the project)",
+		"    - PASS state is one of [OPEN]",
+		"    - FAIL not a draft",
+		"    - PASS author is a user",
+		"    - PASS no delete rule matches",
+		"    - FAIL state is one of [MERGED]",
+		"  Action: SKIP",
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Fatalf("Expected %q in\n%s", line, out.String())
+		}
+	}
+
+	pr.IsDraft = false
+	out.Reset()
+	if err := explainPullRequest(ctx, &out, client, cfg, newMemberships(client, false), "org", "repo", 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "  Action: ADD\n") {
+		t.Fatalf("Expected ADD action in\n%s", out.String())
+	}
+
+	// A sync only looks at pull requests from the job's repositories.
+	job.repoSources = []configRepoSource{{kind: repoSourceName, owner: "org", name: "other"}}
+	cfg.jobs = []configJob{job}
+	out.Reset()
+	if err := explainPullRequest(ctx, &out, client, cfg, newMemberships(client, false), "org", "repo", 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"  Repository org/repo: FAIL",
+		"  Action: SKIP (repository not included)",
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Fatalf("Expected %q in\n%s", line, out.String())
+		}
+	}
+}
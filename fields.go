@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// currentIteration is a special value that refers to the iteration
+// of an iteration field that is in progress.
+const currentIteration = "@current"
+
+// fieldValue is a configured project field value resolved against the project fields.
+type fieldValue struct {
+	field github.ProjectField
+	name  string // Human readable value as configured.
+	value github.ProjectFieldValue
+}
+
+// resolveFieldValues resolves field names and values using the project fields.
+// Values are returned sorted by the field name.
+func resolveFieldValues(fields []github.ProjectField, values map[string]string, now time.Time) ([]fieldValue, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	resolved := make([]fieldValue, 0, len(names))
+	for _, name := range names {
+		field, ok := findByName(fields, name, func(f github.ProjectField) string { return f.Name })
+		if !ok {
+			return nil, fmt.Errorf("field not found: %s", name)
+		}
+
+		value, err := resolveFieldValue(field, values[name], now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %s: %w", name, err)
+		}
+
+		resolved = append(resolved, fieldValue{field: field, name: values[name], value: value})
+	}
+
+	return resolved, nil
+}
+
+// findByName looks up an item by name preferring an exact match over a case-insensitive one.
+func findByName[T any](items []T, name string, nameOf func(T) string) (T, bool) {
+	for _, item := range items {
+		if nameOf(item) == name {
+			return item, true
+		}
+	}
+	for _, item := range items {
+		if strings.EqualFold(nameOf(item), name) {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func resolveFieldValue(field github.ProjectField, value string, now time.Time) (github.ProjectFieldValue, error) {
+	var v github.ProjectFieldValue
+
+	switch field.DataType {
+	case github.ProjectFieldTypeText:
+		v.Text = &value
+	case github.ProjectFieldTypeNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return v, fmt.Errorf("not a number: %s", value)
+		}
+		v.Number = &number
+	case github.ProjectFieldTypeDate:
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return v, fmt.Errorf("not a date (YYYY-MM-DD): %s", value)
+		}
+		v.Date = &value
+	case github.ProjectFieldTypeSingleSelect:
+		option, ok := findByName(field.Options, value, func(o github.ProjectFieldOption) string { return o.Name })
+		if !ok {
+			return v, fmt.Errorf("option not found: %s", value)
+		}
+		v.SingleSelectOptionID = &option.ID
+	case github.ProjectFieldTypeIteration:
+		for _, it := range field.Configuration.Iterations {
+			if value == currentIteration {
+				startDate, err := time.Parse(time.DateOnly, it.StartDate)
+				if err != nil {
+					continue
+				}
+				if !now.Before(startDate) && now.Before(startDate.AddDate(0, 0, it.Duration)) {
+					v.IterationID = &it.ID
+					break
+				}
+				continue
+			}
+			if it.Title == value {
+				v.IterationID = &it.ID
+				break
+			}
+		}
+		if v.IterationID == nil {
+			return v, fmt.Errorf("iteration not found: %s", value)
+		}
+	default:
+		return v, fmt.Errorf("unsupported field type: %s", field.DataType)
+	}
+
+	return v, nil
+}
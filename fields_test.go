@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestResolveFieldValues(t *testing.T) {
+	fields := []github.ProjectField{
+		{ID: "f1", Name: "Status", DataType: github.ProjectFieldTypeSingleSelect, Options: []github.ProjectFieldOption{
+			{ID: "o1", Name: "Todo"},
+			{ID: "o2", Name: "In Review"},
+		}},
+		{ID: "f2", Name: "Team", DataType: github.ProjectFieldTypeText},
+		{ID: "f3", Name: "Estimate", DataType: github.ProjectFieldTypeNumber},
+		{ID: "f4", Name: "Due", DataType: github.ProjectFieldTypeDate},
+		{ID: "f5", Name: "Sprint", DataType: github.ProjectFieldTypeIteration},
+	}
+	fields[4].Configuration.Iterations = []github.ProjectIteration{
+		{ID: "i1", Title: "Sprint 1", StartDate: "2024-01-01", Duration: 14},
+		{ID: "i2", Title: "Sprint 2", StartDate: "2024-01-15", Duration: 14},
+	}
+	now := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	values, err := resolveFieldValues(fields, map[string]string{
+		"status":   "in review",
+		"Team":     "Platform",
+		"Estimate": "2.5",
+		"Due":      "2024-02-01",
+		"Sprint":   currentIteration,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 5, len(values); want != got {
+		t.Fatalf("Expected %d values, got %d", want, got)
+	}
+
+	// Values are sorted by the configured field name.
+	if want, got := "o2", *values[4].value.SingleSelectOptionID; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "f1", values[4].field.ID; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "2024-02-01", *values[0].value.Date; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 2.5, *values[1].value.Number; want != got {
+		t.Fatalf("Expected %f, got %f", want, got)
+	}
+	if want, got := "i2", *values[2].value.IterationID; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "Platform", *values[3].value.Text; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestResolveFieldValuesErrors(t *testing.T) {
+	fields := []github.ProjectField{
+		{ID: "f1", Name: "Status", DataType: github.ProjectFieldTypeSingleSelect, Options: []github.ProjectFieldOption{
+			{ID: "o1", Name: "Todo"},
+		}},
+		{ID: "f2", Name: "Estimate", DataType: github.ProjectFieldTypeNumber},
+		{ID: "f3", Name: "Due", DataType: github.ProjectFieldTypeDate},
+		{ID: "f4", Name: "Sprint", DataType: github.ProjectFieldTypeIteration},
+		{ID: "f5", Name: "Assignees", DataType: "ASSIGNEES"},
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"Unknown", "value"},
+		{"Status", "Done"},
+		{"Estimate", "two"},
+		{"Due", "tomorrow"},
+		{"Sprint", "Sprint 1"},
+		{"Assignees", "user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveFieldValues(fields, map[string]string{tt.name: tt.value}, time.Now())
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
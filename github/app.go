@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is how long an app JWT is valid. GitHub allows up to 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT to allow for clock drift.
+	appJWTClockSkew = time.Minute
+	// installationTokenEarlyExpiry is how long before the expiry an installation token is refreshed.
+	installationTokenEarlyExpiry = 5 * time.Minute
+)
+
+// ParsePrivateKey parses a PEM encoded GitHub App private key.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+
+	return rsaKey, nil
+}
+
+// NewAppTokenSource returns a token source that authenticates as a GitHub App installation.
+// Installation tokens are refreshed automatically before they expire.
+func NewAppTokenSource(ctx context.Context, httpClient *http.Client, githubURL string, appID, installationID int64, key *rsa.PrivateKey) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &appTokenSource{
+		ctx:            ctx,
+		http:           httpClient,
+		githubURL:      githubURL,
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		now:            time.Now,
+	}, installationTokenEarlyExpiry)
+}
+
+// appTokenSource exchanges app JWTs for installation tokens.
+// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/authenticating-as-a-github-app-installation
+type appTokenSource struct {
+	ctx            context.Context
+	http           *http.Client
+	githubURL      string
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	now            func() time.Time
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := appJWT(s.appID, s.key, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("error creating app JWT: %w", err)
+	}
+
+	url := s.githubURL + "/app/installations/" + strconv.FormatInt(s.installationID, 10) + "/access_tokens"
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		message := http.StatusText(resp.StatusCode)
+
+		githubError := &Error{}
+		err = json.NewDecoder(resp.Body).Decode(githubError)
+		if err == nil && githubError.Message != "" {
+			message = githubError.Message
+		}
+
+		return nil, fmt.Errorf("error requesting installation token: %d %s", resp.StatusCode, message)
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("error decoding installation token: %w", err)
+	}
+
+	return &oauth2.Token{AccessToken: token.Token, TokenType: "Bearer", Expiry: token.ExpiresAt}, nil
+}
+
+// appJWT creates a JWT signed with the app private key.
+// See https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+	}{"RS256", "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}{
+		IssuedAt:  now.Add(-appJWTClockSkew).Unix(),
+		ExpiresAt: now.Add(appJWTLifetime).Unix(),
+		Issuer:    strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, block := range map[string]*pem.Block{
+		"pkcs1": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		"pkcs8": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParsePrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !key.Equal(parsed) {
+				t.Fatal("Expected the same key")
+			}
+		})
+	}
+
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestAppJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	jwt, err := appJWT(123, key, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if want, got := 3, len(parts); want != got {
+		t.Fatalf("Expected %d parts, got %d", want, got)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "123", claims.Issuer; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := now.Add(-appJWTClockSkew).Unix(), claims.IssuedAt; want != got {
+		t.Fatalf("Expected %d, got %d", want, got)
+	}
+	if want, got := now.Add(appJWTLifetime).Unix(), claims.ExpiresAt; want != got {
+		t.Fatalf("Expected %d, got %d", want, got)
+	}
+}
+
+func TestAppTokenSourceRefreshes(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if want, got := "/app/installations/456/access_tokens", r.URL.Path; want != got {
+			t.Errorf("Expected %s, got %s", want, got)
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			t.Errorf("Expected a bearer JWT, got %q", r.Header.Get("Authorization"))
+		}
+		// The first token is about to expire and is refreshed on the next use.
+		expiresAt := time.Now().Add(installationTokenEarlyExpiry / 2)
+		if calls > 1 {
+			expiresAt = time.Now().Add(time.Hour)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"token%d","expires_at":%q}`, calls, expiresAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	source := NewAppTokenSource(context.Background(), server.Client(), server.URL, 123, 456, key)
+
+	for _, want := range []string{"token1", "token2", "token2"} {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := token.AccessToken; want != got {
+			t.Fatalf("Expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestAppTokenSourceError(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"A JSON web token could not be decoded"}`)
+	}))
+	defer server.Close()
+
+	source := NewAppTokenSource(context.Background(), server.Client(), server.URL, 123, 456, key)
+
+	_, err = source.Token()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if want, got := "A JSON web token could not be decoded", err.Error(); !strings.Contains(got, want) {
+		t.Fatalf("Expected %q in %q", want, got)
+	}
+}
@@ -7,21 +7,113 @@ import (
 	"iter"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/machinebox/graphql"
 )
 
 type Client struct {
-	githubURL string
-	http      *http.Client
-	graphql   *graphql.Client
+	githubURL   string
+	http        *http.Client
+	graphql     *graphql.Client
+	rateLimiter rateLimiter
+	retrier     retrier
 }
 
 func NewClient(httpClient *http.Client, githubURL string) *Client {
+	// Rate limited and failed requests are retried by the client outside of the HTTP client timeout.
+	retrying := *httpClient
+	retrying.Transport = &serverErrorTransport{base: &rateLimitTransport{base: httpClient.Transport, now: time.Now}}
+
 	return &Client{
 		githubURL: githubURL,
-		http:      httpClient,
-		graphql:   graphql.NewClient(githubURL+"/graphql", graphql.WithHTTPClient(httpClient)),
+		http:      &retrying,
+		graphql:   graphql.NewClient(githubURL+"/graphql", graphql.WithHTTPClient(&retrying)),
+		retrier:   retrier{maxAttempts: DefaultMaxAttempts},
+	}
+}
+
+// OnRateLimit sets a function called before waiting for a rate limit to reset.
+func (c *Client) OnRateLimit(notify func(wait time.Duration)) {
+	c.rateLimiter.notify = notify
+}
+
+// OnRetry sets a function called before retrying a request that failed with a transient error.
+func (c *Client) OnRetry(notify func(attempt int, wait time.Duration, err error)) {
+	c.retrier.notify = notify
+}
+
+// SetMaxAttempts sets how many times a request failing with transient errors is attempted.
+func (c *Client) SetMaxAttempts(maxAttempts int) {
+	c.retrier.maxAttempts = maxAttempts
+}
+
+// RateLimit returns the GraphQL rate limit usage of the client.
+func (c *Client) RateLimit() RateLimitUsage {
+	c.rateLimiter.mu.Lock()
+	defer c.rateLimiter.mu.Unlock()
+
+	return c.rateLimiter.usage
+}
+
+// run runs an idempotent GraphQL query or mutation
+// retrying it if it's rate limited or fails with a transient error.
+func (c *Client) run(ctx context.Context, req *graphql.Request, resp any) error {
+	return c.runWithRetries(ctx, req, resp, true)
+}
+
+// runOnce runs a GraphQL mutation that is not safe to repeat.
+// It's only retried if rate limited since such requests are rejected without being processed.
+func (c *Client) runOnce(ctx context.Context, req *graphql.Request, resp any) error {
+	return c.runWithRetries(ctx, req, resp, false)
+}
+
+func (c *Client) runWithRetries(ctx context.Context, req *graphql.Request, resp any, idempotent bool) error {
+	var rateLimitAttempt, attempt int
+	for {
+		rateLimited := &rateLimitedResponse{resp: resp}
+		err := c.graphql.Run(ctx, req, rateLimited)
+		c.rateLimiter.record(rateLimited.rateLimit)
+		if err == nil {
+			return nil
+		}
+
+		if isRateLimited(err) {
+			rateLimitAttempt++
+			if !c.rateLimiter.wait(ctx, err, rateLimitAttempt) {
+				return err
+			}
+			continue
+		}
+
+		attempt++
+		if !idempotent || !c.retrier.wait(ctx, err, attempt) {
+			return err
+		}
+	}
+}
+
+// do sends an idempotent REST request retrying it if it's rate limited or fails with a transient error.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	var rateLimitAttempt, attempt int
+	for {
+		resp, err := c.http.Do(req.Clone(req.Context()))
+		if err == nil {
+			return resp, nil
+		}
+
+		if isRateLimited(err) {
+			rateLimitAttempt++
+			if !c.rateLimiter.wait(req.Context(), err, rateLimitAttempt) {
+				return nil, err
+			}
+			continue
+		}
+
+		attempt++
+		if !c.retrier.wait(req.Context(), err, attempt) {
+			return nil, err
+		}
 	}
 }
 
@@ -32,7 +124,7 @@ func (c *Client) GetRepositoryPullRequests(ctx context.Context, owner string, na
 			var resp PullRequestResponse
 
 			req := NewPullRequestsRequest(owner, name, states, 100, after)
-			if err := c.graphql.Run(ctx, req, &resp); err != nil {
+			if err := c.run(ctx, req, &resp); err != nil {
 				yield(nil, err)
 				return
 			}
@@ -47,6 +139,10 @@ func (c *Client) GetRepositoryPullRequests(ctx context.Context, owner string, na
 			}
 
 			for _, pr := range resp.Repository.PullRequests.Nodes {
+				if err := c.fetchPullRequestConnections(ctx, &pr); err != nil {
+					yield(nil, err)
+					return
+				}
 				if !yield(&pr, nil) {
 					return
 				}
@@ -61,36 +157,216 @@ func (c *Client) GetRepositoryPullRequests(ctx context.Context, owner string, na
 	}
 }
 
-func (c *Client) GetProject(ctx context.Context, owner string, number int) (*Project, error) {
+// GetPullRequest looks up a single pull request by its repository and number.
+func (c *Client) GetPullRequest(ctx context.Context, owner, name string, number int) (*PullRequest, error) {
+	var resp SinglePullRequestResponse
+
+	req := NewPullRequestRequest(owner, name, number)
+	if err := c.run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Errors != nil {
+		return nil, resp.Errors
+	}
+
+	if resp.Repository == nil {
+		return nil, fmt.Errorf("repository not found")
+	}
+	if resp.Repository.PullRequest == nil {
+		return nil, fmt.Errorf("pull request not found")
+	}
+
+	if err := c.fetchPullRequestConnections(ctx, resp.Repository.PullRequest); err != nil {
+		return nil, err
+	}
+
+	return resp.Repository.PullRequest, nil
+}
+
+func (c *Client) GetRepositoryIssues(ctx context.Context, owner string, name string, states []IssueState) iter.Seq2[*Issue, error] {
+	return func(yield func(*Issue, error) bool) {
+		var after string
+		for {
+			var resp IssueResponse
+
+			req := NewIssuesRequest(owner, name, states, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+
+			if resp.Repository == nil {
+				yield(nil, fmt.Errorf("repository not found"))
+				return
+			}
+
+			for _, issue := range resp.Repository.Issues.Nodes {
+				if err := c.fetchAssignees(ctx, issue.ID, &issue.Assignees); err != nil {
+					yield(nil, fmt.Errorf("error fetching assignees of %s: %w", issue.URL, err))
+					return
+				}
+				if !yield(&issue, nil) {
+					return
+				}
+			}
+
+			if !resp.Repository.Issues.PageInfo.HasNextPage {
+				break
+			}
+
+			after = resp.Repository.Issues.PageInfo.EndCursor
+		}
+	}
+}
+
+// GetOwnerRepositories returns an iterator over all repositories of an organization or a user.
+func (c *Client) GetOwnerRepositories(ctx context.Context, owner string) iter.Seq2[*Repository, error] {
+	return func(yield func(*Repository, error) bool) {
+		var after string
+		for {
+			var resp OwnerRepositoriesResponse
+
+			req := NewOwnerRepositoriesRequest(owner, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+
+			if resp.Owner == nil {
+				yield(nil, fmt.Errorf("repository owner not found"))
+				return
+			}
+
+			for _, repo := range resp.Owner.Repositories.Nodes {
+				if !yield(&repo, nil) {
+					return
+				}
+			}
+
+			if !resp.Owner.Repositories.PageInfo.HasNextPage {
+				break
+			}
+
+			after = resp.Owner.Repositories.PageInfo.EndCursor
+		}
+	}
+}
+
+// GetTeamRepositories returns an iterator over all repositories the team has access to.
+func (c *Client) GetTeamRepositories(ctx context.Context, org, team string) iter.Seq2[*Repository, error] {
+	return func(yield func(*Repository, error) bool) {
+		var after string
+		for {
+			var resp TeamRepositoriesResponse
+
+			req := NewTeamRepositoriesRequest(org, team, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+
+			if resp.Organization == nil || resp.Organization.Team == nil {
+				yield(nil, fmt.Errorf("team not found"))
+				return
+			}
+
+			for _, repo := range resp.Organization.Team.Repositories.Nodes {
+				if !yield(&repo, nil) {
+					return
+				}
+			}
+
+			if !resp.Organization.Team.Repositories.PageInfo.HasNextPage {
+				break
+			}
+
+			after = resp.Organization.Team.Repositories.PageInfo.EndCursor
+		}
+	}
+}
+
+// SearchRepositories returns an iterator over repositories matching the search query.
+func (c *Client) SearchRepositories(ctx context.Context, query string) iter.Seq2[*Repository, error] {
+	return func(yield func(*Repository, error) bool) {
+		var after string
+		for {
+			var resp SearchRepositoriesResponse
+
+			req := NewSearchRepositoriesRequest(query, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+
+			for _, repo := range resp.Search.Nodes {
+				if !yield(&repo, nil) {
+					return
+				}
+			}
+
+			if !resp.Search.PageInfo.HasNextPage {
+				break
+			}
+
+			after = resp.Search.PageInfo.EndCursor
+		}
+	}
+}
+
+// GetProject looks up a project by its owner and number.
+// The owner is resolved as either an organization or a user if ownerType is ProjectOwnerTypeAny.
+func (c *Client) GetProject(ctx context.Context, owner string, ownerType ProjectOwnerType, number int) (*Project, error) {
 	var resp ProjectResponse
 
-	req := NewProjectRequest(owner, number)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+	req := NewProjectRequest(owner, ownerType, number)
+	if err := c.run(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 	if resp.Errors != nil {
 		return nil, resp.Errors
 	}
 
-	if resp.Organization == nil || resp.Organization.Project == nil {
+	if resp.Owner == nil || resp.Owner.Project == nil {
 		return nil, fmt.Errorf("project not found")
 	}
 
-	return &Project{
-		ID:     resp.Organization.Project.ID,
-		Number: resp.Organization.Project.Number,
-		Title:  resp.Organization.Project.Title,
-	}, nil
+	project := &Project{
+		ID:              resp.Owner.Project.ID,
+		Number:          resp.Owner.Project.Number,
+		Title:           resp.Owner.Project.Title,
+		ViewerCanUpdate: resp.Owner.Project.ViewerCanUpdate,
+	}
+	project.Owner.Type = resp.Owner.Type
+	project.Owner.Login = resp.Owner.Login
+
+	return project, nil
 }
 
-func (c *Client) GetProjectPullRequests(ctx context.Context, owner string, number int) iter.Seq2[*PullRequest, error] {
-	return func(yield func(*PullRequest, error) bool) {
+// projectItems returns an iterator over all items of the project.
+func (c *Client) projectItems(ctx context.Context, owner string, ownerType ProjectOwnerType, number int) iter.Seq2[*ProjectItem, error] {
+	return func(yield func(*ProjectItem, error) bool) {
 		var after string
 		for {
 			var resp ProjectItemsResponse
 
-			req := NewProjectItemsRequest(owner, number, 100, after)
-			if err := c.graphql.Run(ctx, req, &resp); err != nil {
+			req := NewProjectItemsRequest(owner, ownerType, number, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
 				yield(nil, err)
 				return
 			}
@@ -99,38 +375,157 @@ func (c *Client) GetProjectPullRequests(ctx context.Context, owner string, numbe
 				return
 			}
 
-			if resp.Organization == nil || resp.Organization.Project == nil {
+			if resp.Owner == nil || resp.Owner.Project == nil {
 				yield(nil, fmt.Errorf("project not found"))
 				return
 			}
 
-			for _, item := range resp.Organization.Project.Items.Nodes {
-				if item.Type != ProjectItemTypePullRequest {
-					continue
+			for _, item := range resp.Owner.Project.Items.Nodes {
+				if !yield(&item, nil) {
+					return
 				}
+			}
+
+			if !resp.Owner.Project.Items.PageInfo.HasNextPage {
+				break
+			}
 
-				pr := *item.PullRequest
-				pr.ProjectItemID = item.ID
+			after = resp.Owner.Project.Items.PageInfo.EndCursor
+		}
+	}
+}
 
-				if !yield(&pr, nil) {
+func (c *Client) GetProjectPullRequests(ctx context.Context, owner string, ownerType ProjectOwnerType, number int) iter.Seq2[*PullRequest, error] {
+	return func(yield func(*PullRequest, error) bool) {
+		for item, err := range c.projectItems(ctx, owner, ownerType, number) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if item.Type != ProjectItemTypePullRequest {
+				continue
+			}
+
+			pr := *item.PullRequest
+			pr.ProjectItemID = item.ID
+			pr.ProjectItem = item
+
+			if !yield(&pr, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) GetProjectIssues(ctx context.Context, owner string, ownerType ProjectOwnerType, number int) iter.Seq2[*Issue, error] {
+	return func(yield func(*Issue, error) bool) {
+		for item, err := range c.projectItems(ctx, owner, ownerType, number) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if item.Type != ProjectItemTypeIssue {
+				continue
+			}
+
+			issue := *item.Issue
+			issue.ProjectItemID = item.ID
+			issue.ProjectItem = item
+
+			if !yield(&issue, nil) {
+				return
+			}
+		}
+	}
+}
+
+// GetTeamMembers returns an iterator over the team members.
+// Membership defaults to ALL, i.e. members of the child teams are included.
+func (c *Client) GetTeamMembers(ctx context.Context, teamOrg, teamName string, membership TeamMembership) iter.Seq2[*User, error] {
+	if membership == "" {
+		membership = TeamMembershipAll
+	}
+
+	return func(yield func(*User, error) bool) {
+		var after string
+		for {
+			var resp TeamMembersResponse
+
+			req := NewTeamMembersRequest(teamOrg, teamName, membership, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+			if resp.Organization == nil || resp.Organization.Team == nil {
+				yield(nil, fmt.Errorf("team not found"))
+				return
+			}
+
+			members := resp.Organization.Team.Members
+			for _, user := range members.Nodes {
+				if !yield(&user, nil) {
+					return
+				}
+			}
+
+			if !members.PageInfo.HasNextPage {
+				break
+			}
+
+			after = members.PageInfo.EndCursor
+		}
+	}
+}
+
+// GetChildTeams returns an iterator over all child teams of the team at any depth.
+func (c *Client) GetChildTeams(ctx context.Context, teamOrg, teamName string) iter.Seq2[*Team, error] {
+	return func(yield func(*Team, error) bool) {
+		var after string
+		for {
+			var resp ChildTeamsResponse
+
+			req := NewChildTeamsRequest(teamOrg, teamName, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+			if resp.Organization == nil || resp.Organization.Team == nil {
+				yield(nil, fmt.Errorf("team not found"))
+				return
+			}
+
+			children := resp.Organization.Team.ChildTeams
+			for _, team := range children.Nodes {
+				if !yield(&team, nil) {
 					return
 				}
 			}
 
-			if !resp.Organization.Project.Items.PageInfo.HasNextPage {
+			if !children.PageInfo.HasNextPage {
 				break
 			}
 
-			after = resp.Organization.Project.Items.PageInfo.EndCursor
+			after = children.PageInfo.EndCursor
 		}
 	}
 }
 
-func (c *Client) GetTeamMembers(ctx context.Context, teamOrg, teamName string) ([]User, error) {
-	var resp TeamMembersResponse
+// GetTeam returns the team if it's visible to the viewer.
+func (c *Client) GetTeam(ctx context.Context, org, team string) (*Team, error) {
+	var resp TeamResponse
 
-	req := NewTeamMembersRequest(teamOrg, teamName, 100, "")
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+	req := NewTeamRequest(org, team)
+	if err := c.run(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 	if resp.Errors != nil {
@@ -140,14 +535,93 @@ func (c *Client) GetTeamMembers(ctx context.Context, teamOrg, teamName string) (
 		return nil, fmt.Errorf("team not found")
 	}
 
-	return resp.Organization.Team.Members.Nodes, nil
+	return resp.Organization.Team, nil
+}
+
+// GetRepository returns the repository if it's visible to the viewer.
+func (c *Client) GetRepository(ctx context.Context, owner, name string) (*Repository, error) {
+	var resp RepositoryResponse
+
+	req := NewRepositoryRequest(owner, name)
+	if err := c.run(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Errors != nil {
+		return nil, resp.Errors
+	}
+	if resp.Repository == nil {
+		return nil, fmt.Errorf("repository not found")
+	}
+
+	return resp.Repository, nil
 }
 
-func (c *Client) AddPullRequestToProject(ctx context.Context, projectID, pullRequestID string) error {
-	var resp AddPullRequestToProjectResponse
+// AddPullRequestToProject adds the pull request to the project and returns the project item ID.
+func (c *Client) AddPullRequestToProject(ctx context.Context, projectID, pullRequestID string) (string, error) {
+	return c.addProjectItem(ctx, projectID, pullRequestID)
+}
+
+// AddIssueToProject adds the issue to the project and returns the project item ID.
+func (c *Client) AddIssueToProject(ctx context.Context, projectID, issueID string) (string, error) {
+	return c.addProjectItem(ctx, projectID, issueID)
+}
 
-	req := NewAddPullRequestToProjectRequest(projectID, pullRequestID)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+func (c *Client) addProjectItem(ctx context.Context, projectID, contentID string) (string, error) {
+	var resp AddProjectItemResponse
+
+	req := NewAddProjectItemRequest(projectID, contentID)
+	if err := c.run(ctx, req, &resp); err != nil {
+		return "", err
+	}
+	if resp.Errors != nil {
+		return "", resp.Errors
+	}
+
+	if resp.AddProjectV2ItemByID.Item == nil {
+		return "", fmt.Errorf("project item not returned")
+	}
+
+	return resp.AddProjectV2ItemByID.Item.ID, nil
+}
+
+// GetProjectFields returns all fields of the project.
+func (c *Client) GetProjectFields(ctx context.Context, projectID string) ([]ProjectField, error) {
+	var (
+		fields []ProjectField
+		after  string
+	)
+	for {
+		var resp ProjectFieldsResponse
+
+		req := NewProjectFieldsRequest(projectID, 100, after)
+		if err := c.run(ctx, req, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Errors != nil {
+			return nil, resp.Errors
+		}
+
+		if resp.Node == nil {
+			return nil, fmt.Errorf("project not found")
+		}
+
+		fields = append(fields, resp.Node.Fields.Nodes...)
+
+		if !resp.Node.Fields.PageInfo.HasNextPage {
+			break
+		}
+
+		after = resp.Node.Fields.PageInfo.EndCursor
+	}
+
+	return fields, nil
+}
+
+func (c *Client) UpdateProjectItemFieldValue(ctx context.Context, projectID, itemID, fieldID string, value ProjectFieldValue) error {
+	var resp UpdateProjectItemFieldValueResponse
+
+	req := NewUpdateProjectItemFieldValueRequest(projectID, itemID, fieldID, value)
+	if err := c.run(ctx, req, &resp); err != nil {
 		return err
 	}
 	if resp.Errors != nil {
@@ -157,11 +631,25 @@ func (c *Client) AddPullRequestToProject(ctx context.Context, projectID, pullReq
 	return nil
 }
 
-func (c *Client) DeletePullRequestFromProject(ctx context.Context, projectID, itemID string) error {
-	var resp DeletePullRequestFromProjectResponse
+func (c *Client) DeleteProjectItem(ctx context.Context, projectID, itemID string) error {
+	var resp DeleteProjectItemResponse
 
-	req := NewDeletePullRequestFromProjectRequest(projectID, itemID)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+	req := NewDeleteProjectItemRequest(projectID, itemID)
+	if err := c.runOnce(ctx, req, &resp); err != nil {
+		return err
+	}
+	if resp.Errors != nil {
+		return resp.Errors
+	}
+
+	return nil
+}
+
+func (c *Client) ArchiveProjectItem(ctx context.Context, projectID, itemID string) error {
+	var resp ArchiveProjectItemResponse
+
+	req := NewArchiveProjectItemRequest(projectID, itemID)
+	if err := c.run(ctx, req, &resp); err != nil {
 		return err
 	}
 	if resp.Errors != nil {
@@ -172,10 +660,18 @@ func (c *Client) DeletePullRequestFromProject(ctx context.Context, projectID, it
 }
 
 func (c *Client) AddAssigneeToPullRequest(ctx context.Context, pullRequestID, userID string) error {
-	var resp AddAssigneeToPullRequestResponse
+	return c.addAssignee(ctx, pullRequestID, userID)
+}
+
+func (c *Client) AddAssigneeToIssue(ctx context.Context, issueID, userID string) error {
+	return c.addAssignee(ctx, issueID, userID)
+}
 
-	req := NewAddAssigneeToPullRequestRequest(pullRequestID, userID)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+func (c *Client) addAssignee(ctx context.Context, assignableID, userID string) error {
+	var resp AddAssigneeResponse
+
+	req := NewAddAssigneeRequest(assignableID, userID)
+	if err := c.run(ctx, req, &resp); err != nil {
 		return err
 	}
 	if resp.Errors != nil {
@@ -189,7 +685,7 @@ func (c *Client) LookupUser(ctx context.Context, login string) (*User, error) {
 	var resp LookupUserResponse
 
 	req := NewLookupUserRequest(login)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
+	if err := c.run(ctx, req, &resp); err != nil {
 		return nil, err
 	}
 	if resp.Errors != nil {
@@ -203,18 +699,98 @@ func (c *Client) LookupUser(ctx context.Context, login string) (*User, error) {
 	return resp.User, nil
 }
 
-func (c *Client) GetUserOrganizations(ctx context.Context, login string) ([]Organization, error) {
-	var resp LookupUserMembershipResponse
+func (c *Client) GetUserOrganizations(ctx context.Context, login string) iter.Seq2[*Organization, error] {
+	return func(yield func(*Organization, error) bool) {
+		var after string
+		for {
+			var resp LookupUserMembershipResponse
 
-	req := NewLookupUserMembershipRequest(login)
-	if err := c.graphql.Run(ctx, req, &resp); err != nil {
-		return nil, err
+			req := NewLookupUserMembershipRequest(login, 100, after)
+			if err := c.run(ctx, req, &resp); err != nil {
+				yield(nil, err)
+				return
+			}
+			if resp.Errors != nil {
+				yield(nil, resp.Errors)
+				return
+			}
+			if resp.User == nil {
+				return
+			}
+
+			orgs := resp.User.Organizations
+			for _, org := range orgs.Nodes {
+				if !yield(&org, nil) {
+					return
+				}
+			}
+
+			if !orgs.PageInfo.HasNextPage {
+				break
+			}
+
+			after = orgs.PageInfo.EndCursor
+		}
 	}
-	if resp.Errors != nil {
-		return nil, resp.Errors
+}
+
+// fetchAssignees fetches the rest of the assignees of a pull request or an issue
+// if they don't fit into the first page.
+func (c *Client) fetchAssignees(ctx context.Context, assignableID string, assignees *UserConnection) error {
+	for assignees.PageInfo.HasNextPage {
+		var resp AssigneesResponse
+
+		req := NewAssigneesRequest(assignableID, 100, assignees.PageInfo.EndCursor)
+		if err := c.run(ctx, req, &resp); err != nil {
+			return err
+		}
+		if resp.Errors != nil {
+			return resp.Errors
+		}
+		if resp.Node == nil {
+			return fmt.Errorf("assignable not found")
+		}
+
+		assignees.Nodes = append(assignees.Nodes, resp.Node.Assignees.Nodes...)
+		assignees.PageInfo = resp.Node.Assignees.PageInfo
+	}
+
+	return nil
+}
+
+// fetchPullRequestProjects fetches the rest of the projects of a pull request
+// if they don't fit into the first page.
+func (c *Client) fetchPullRequestProjects(ctx context.Context, pr *PullRequest) error {
+	for pr.Projects.PageInfo.HasNextPage {
+		var resp PullRequestProjectsResponse
+
+		req := NewPullRequestProjectsRequest(pr.ID, 100, pr.Projects.PageInfo.EndCursor)
+		if err := c.run(ctx, req, &resp); err != nil {
+			return err
+		}
+		if resp.Errors != nil {
+			return resp.Errors
+		}
+		if resp.Node == nil {
+			return fmt.Errorf("pull request not found")
+		}
+
+		pr.Projects.Nodes = append(pr.Projects.Nodes, resp.Node.Projects.Nodes...)
+		pr.Projects.PageInfo = resp.Node.Projects.PageInfo
 	}
 
-	return resp.User.Organizations.Nodes, nil
+	return nil
+}
+
+// fetchPullRequestConnections fetches the rest of the pages of the pull request's nested connections.
+func (c *Client) fetchPullRequestConnections(ctx context.Context, pr *PullRequest) error {
+	if err := c.fetchAssignees(ctx, pr.ID, &pr.Assignees); err != nil {
+		return fmt.Errorf("error fetching assignees of %s: %w", pr.URL, err)
+	}
+	if err := c.fetchPullRequestProjects(ctx, pr); err != nil {
+		return fmt.Errorf("error fetching projects of %s: %w", pr.URL, err)
+	}
+	return nil
 }
 
 func (c *Client) IsOrganizationMember(ctx context.Context, login, org string) (bool, error) {
@@ -227,7 +803,7 @@ func (c *Client) IsOrganizationMember(ctx context.Context, login, org string) (b
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.http.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return false, fmt.Errorf("error making request: %w", err)
 	}
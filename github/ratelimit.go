@@ -0,0 +1,201 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries is how many times a rate limited request is retried.
+	maxRateLimitRetries = 3
+	// maxRateLimitWait is the longest wait for a rate limit to reset before giving up.
+	maxRateLimitWait = time.Hour
+	// secondaryRateLimitWait is the wait for a secondary rate limit without a Retry-After header.
+	secondaryRateLimitWait = time.Minute
+)
+
+// RateLimit is the GraphQL rate limit status returned with every query.
+type RateLimit struct {
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// RateLimitUsage is the GraphQL rate limit consumed by the client.
+type RateLimitUsage struct {
+	Used      int       // Points consumed by all queries so far.
+	Remaining int       // Points remaining as of the last query.
+	ResetAt   time.Time // When the remaining points reset.
+}
+
+// RateLimitError is returned when GitHub rejects a request because of a primary or secondary rate limit.
+type RateLimitError struct {
+	StatusCode int
+	Wait       time.Duration // How long to wait before retrying.
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded (%d), retry in %s", e.StatusCode, e.Wait)
+}
+
+// rateLimitTransport turns rate limited responses into RateLimitErrors
+// so that the client can wait outside of the HTTP client timeout and retry.
+type rateLimitTransport struct {
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wait, limited, err := rateLimitWait(resp, t.now())
+	if err != nil || !limited {
+		return resp, err
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return nil, &RateLimitError{StatusCode: resp.StatusCode, Wait: wait}
+}
+
+// rateLimitWait reports whether the response was rejected by a rate limit and how long to wait.
+// See https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool, error) {
+	exhausted := resp.Header.Get("X-RateLimit-Remaining") == "0"
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+	case http.StatusOK:
+		// GraphQL reports an exhausted primary rate limit as a RATE_LIMITED error.
+		if !exhausted {
+			return 0, false, nil
+		}
+		body, err := peekBody(resp)
+		if err != nil {
+			return 0, false, err
+		}
+		if !bytes.Contains(body, []byte(`"RATE_LIMITED"`)) {
+			return 0, false, nil
+		}
+	default:
+		return 0, false, nil
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true, nil
+	}
+
+	if exhausted {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0) + time.Second, true, nil
+		}
+	}
+
+	// A secondary rate limit without a Retry-After header.
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryRateLimitWait, true, nil
+	}
+
+	// A secondary rate limit can also be a 403 that only says so in the message.
+	body, err := peekBody(resp)
+	if err != nil {
+		return 0, false, err
+	}
+	if bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit")) {
+		return secondaryRateLimitWait, true, nil
+	}
+
+	// Forbidden for other reasons.
+	return 0, false, nil
+}
+
+// peekBody reads the response body and replaces it so that it can be read again.
+func peekBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+func isRateLimited(err error) bool {
+	var rateLimitErr *RateLimitError
+	return errors.As(err, &rateLimitErr)
+}
+
+// rateLimitedResponse captures the rate limit of a GraphQL query alongside the response.
+type rateLimitedResponse struct {
+	resp      any
+	rateLimit *RateLimit
+}
+
+func (r *rateLimitedResponse) UnmarshalJSON(data []byte) error {
+	var rateLimit struct {
+		RateLimit *RateLimit `json:"rateLimit"`
+	}
+	if err := json.Unmarshal(data, &rateLimit); err != nil {
+		return err
+	}
+	r.rateLimit = rateLimit.RateLimit
+
+	return json.Unmarshal(data, r.resp)
+}
+
+// rateLimiter accumulates the rate limit usage and waits out rate limits.
+type rateLimiter struct {
+	mu     sync.Mutex
+	usage  RateLimitUsage
+	notify func(wait time.Duration)
+}
+
+func (l *rateLimiter) record(rateLimit *RateLimit) {
+	if rateLimit == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.usage.Used += rateLimit.Cost
+	l.usage.Remaining = rateLimit.Remaining
+	l.usage.ResetAt = rateLimit.ResetAt
+}
+
+// wait waits out the rate limit if the request failed because of it
+// and reports whether the request should be retried.
+func (l *rateLimiter) wait(ctx context.Context, err error, attempt int) bool {
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || attempt > maxRateLimitRetries || rateLimitErr.Wait > maxRateLimitWait {
+		return false
+	}
+
+	if l.notify != nil {
+		l.notify(rateLimitErr.Wait)
+	}
+
+	timer := time.NewTimer(rateLimitErr.Wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		body    string
+		wait    time.Duration
+		limited bool
+	}{
+		{"ok", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "10"}, `{"data":{}}`, 0, false},
+		{"retry after", http.StatusForbidden, map[string]string{"Retry-After": "30"}, "", 30 * time.Second, true},
+		{"primary reset", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()+60, 10)}, "", 61 * time.Second, true},
+		{"secondary", http.StatusTooManyRequests, nil, "", secondaryRateLimitWait, true},
+		{"secondary forbidden", http.StatusForbidden, nil, `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`, secondaryRateLimitWait, true},
+		{"forbidden", http.StatusForbidden, nil, `{"message":"Resource not accessible by integration"}`, 0, false},
+		{"graphql rate limited", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()+10, 10)}, `{"errors":[{"type":"RATE_LIMITED"}]}`, 11 * time.Second, true},
+		{"graphql last point", http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0"}, `{"data":{}}`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(tt.body))}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			wait, limited, err := rateLimitWait(resp, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.limited, limited; want != got {
+				t.Fatalf("Expected %t, got %t", want, got)
+			}
+			if want, got := tt.wait, wait; want != got {
+				t.Fatalf("Expected %s, got %s", want, got)
+			}
+
+			// The body is still readable.
+			body, _ := io.ReadAll(resp.Body)
+			if want, got := tt.body, string(body); want != got {
+				t.Fatalf("Expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestClientRetriesRateLimited(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"rateLimit":{"cost":%d,"remaining":4990,"resetAt":"2024-01-01T00:00:00Z"},"user":{"id":"U1","login":"user"}}}`, calls)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+	var waits int
+	client.OnRateLimit(func(time.Duration) { waits++ })
+
+	user, err := client.LookupUser(context.Background(), "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "U1", user.ID; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 2, calls; want != got {
+		t.Fatalf("Expected %d calls, got %d", want, got)
+	}
+	if want, got := 1, waits; want != got {
+		t.Fatalf("Expected %d waits, got %d", want, got)
+	}
+
+	usage := client.RateLimit()
+	if want, got := 2, usage.Used; want != got {
+		t.Fatalf("Expected %d points used, got %d", want, got)
+	}
+	if want, got := 4990, usage.Remaining; want != got {
+		t.Fatalf("Expected %d points remaining, got %d", want, got)
+	}
+}
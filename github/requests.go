@@ -1,21 +1,171 @@
 package github
 
 import (
+	"fmt"
+
 	"github.com/machinebox/graphql"
 )
 
 func NewPullRequestsRequest(owner, name string, states []PullRequestState, first int, after string) *graphql.Request {
 	query := `
   query repositoryPullRequests($owner: String!, $name: String!, $states: [PullRequestState!], $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
       repository(owner: $owner, name: $name) {
           id
           nameWithOwner
           pullRequests(states: $states, first: $first, after: $after) {
+              totalCount
+              nodes {
+                  ...pullRequest
+              }
+              pageInfo {
+                  endCursor
+                  hasNextPage
+                  hasPreviousPage
+                  startCursor
+              }
+          }
+      }
+  }
+` + pullRequestFragment + pullRequestStatusFragment
+
+	req := graphql.NewRequest(query)
+	req.Var("owner", owner)
+	req.Var("name", name)
+	req.Var("states", states)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+// NewPullRequestRequest looks up a single pull request
+// including the project items it belongs to.
+func NewPullRequestRequest(owner, name string, number int) *graphql.Request {
+	query := `
+  query repositoryPullRequest($owner: String!, $name: String!, $number: Int!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+      repository(owner: $owner, name: $name) {
+          pullRequest(number: $number) {
+              ...pullRequest
+              projectItems(first: 100) {
+                totalCount
+                nodes {
+                  id
+                  isArchived
+                  project {
+                    id
+                    number
+                    title
+                  }
+                  ...projectItemFieldValues
+                }
+              }
+          }
+      }
+  }
+` + pullRequestFragment + pullRequestStatusFragment + projectItemFieldValuesFragment
+
+	req := graphql.NewRequest(query)
+	req.Var("owner", owner)
+	req.Var("name", name)
+	req.Var("number", number)
+
+	return req
+}
+
+const pullRequestFragment = `
+  fragment pullRequest on PullRequest {
+      id
+      number
+      isDraft
+      title
+      createdAt
+      updatedAt
+      author {
+        type: __typename
+        login
+      }
+      repository {
+        id
+        owner{
+          login
+        }
+        name
+      }
+      url
+      state
+      reviewDecision
+      ...pullRequestStatus
+      baseRefName
+      headRefName
+      labels(first: 100) {
+        totalCount
+        nodes {
+          name
+        }
+      }
+      assignees(first:100) {
+        totalCount
+        nodes {
+          login
+        }
+        pageInfo {
+          endCursor
+          hasNextPage
+        }
+      }
+      projects: projectsV2(first: 100) {
+        ...pullRequestProjects
+      }
+  }` + pullRequestProjectsFragment
+
+const pullRequestProjectsFragment = `
+  fragment pullRequestProjects on ProjectV2Connection {
+      totalCount
+      nodes {
+        id
+        number
+        title
+        owner {
+          ...on Organization {
+              login
+          }
+          ...on User {
+              login
+          }
+        }
+      }
+      pageInfo {
+        endCursor
+        hasNextPage
+      }
+  }`
+
+func NewIssuesRequest(owner, name string, states []IssueState, first int, after string) *graphql.Request {
+	query := `
+  query repositoryIssues($owner: String!, $name: String!, $states: [IssueState!], $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+      repository(owner: $owner, name: $name) {
+          id
+          nameWithOwner
+          issues(states: $states, first: $first, after: $after) {
               totalCount
               nodes {
                   id
                   number
-                  isDraft
                   title
                   createdAt
                   updatedAt
@@ -37,23 +187,11 @@ func NewPullRequestsRequest(owner, name string, states []PullRequestState, first
                     nodes {
                       login
                     }
-                  }
-                  projects: projectsV2(first: 100) {
-                    totalCount
-                    nodes {
-                      id
-                      number
-                      title
-                      owner {
-                        ...on Organization {
-                            login
-                        }
-                        ...on User {
-                            login
-                        }
-                      }
+                    pageInfo {
+                      endCursor
+                      hasNextPage
                     }
-                }
+                  }
               }
               pageInfo {
                   endCursor
@@ -75,12 +213,127 @@ func NewPullRequestsRequest(owner, name string, states []PullRequestState, first
 	return req
 }
 
-func NewTeamMembersRequest(org, team string, first int, after string) *graphql.Request {
+func NewOwnerRepositoriesRequest(owner string, first int, after string) *graphql.Request {
+	query := `
+  query ownerRepositories($owner: String!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    owner: repositoryOwner(login: $owner) {
+      repositories(first: $first, after: $after, orderBy: {field: NAME, direction: ASC}) {
+        totalCount
+        nodes {
+          ...repository
+        }
+        pageInfo {
+          endCursor
+          hasNextPage
+          hasPreviousPage
+          startCursor
+        }
+      }
+    }
+  }
+` + repositoryFragment
+
+	req := graphql.NewRequest(query)
+	req.Var("owner", owner)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+func NewTeamRepositoriesRequest(org, team string, first int, after string) *graphql.Request {
 	query := `
-  query teamMembers($org: String!, $team: String!, $first: Int!, $after: String!) {
+  query teamRepositories($org: String!, $team: String!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
     organization(login: $org) {
       team(slug: $team) {
-        members (first: $first, after: $after) {
+        repositories(first: $first, after: $after, orderBy: {field: NAME, direction: ASC}) {
+          totalCount
+          nodes {
+            ...repository
+          }
+          pageInfo {
+            endCursor
+            hasNextPage
+            hasPreviousPage
+            startCursor
+          }
+        }
+      }
+    }
+  }
+` + repositoryFragment
+
+	req := graphql.NewRequest(query)
+	req.Var("org", org)
+	req.Var("team", team)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+func NewSearchRepositoriesRequest(query string, first int, after string) *graphql.Request {
+	search := `
+  query searchRepositories($query: String!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    search(query: $query, type: REPOSITORY, first: $first, after: $after) {
+      totalCount: repositoryCount
+      nodes {
+        ...repository
+      }
+      pageInfo {
+        endCursor
+        hasNextPage
+        hasPreviousPage
+        startCursor
+      }
+    }
+  }
+` + repositoryFragment
+
+	req := graphql.NewRequest(search)
+	req.Var("query", query)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+const repositoryFragment = `
+  fragment repository on Repository {
+    name
+    owner {
+      login
+    }
+    isArchived
+    isFork
+  }`
+
+func NewTeamMembersRequest(org, team string, membership TeamMembership, first int, after string) *graphql.Request {
+	query := `
+  query teamMembers($org: String!, $team: String!, $membership: TeamMembershipType!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    organization(login: $org) {
+      team(slug: $team) {
+        members (first: $first, after: $after, membership: $membership) {
           totalCount
           nodes {
             id
@@ -101,72 +354,402 @@ func NewTeamMembersRequest(org, team string, first int, after string) *graphql.R
 	req := graphql.NewRequest(query)
 	req.Var("org", org)
 	req.Var("team", team)
+	req.Var("membership", membership)
 	req.Var("first", first)
 	req.Var("after", after)
 
 	return req
 }
 
-func NewProjectRequest(owner string, number int) *graphql.Request {
+func NewChildTeamsRequest(org, team string, first int, after string) *graphql.Request {
 	query := `
-  query projectPullRequests ($owner: String!, $number: Int!) {
-    organization(login: $owner) {
-      projectV2(number: $number) {
+  query childTeams($org: String!, $team: String!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    organization(login: $org) {
+      team(slug: $team) {
+        childTeams (first: $first, after: $after, immediateOnly: false) {
+          nodes {
+            id
+            name
+            slug
+          }
+          pageInfo {
+            endCursor
+            hasNextPage
+            hasPreviousPage
+            startCursor
+          }
+        }
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(query)
+	req.Var("org", org)
+	req.Var("team", team)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+func NewTeamRequest(org, team string) *graphql.Request {
+	query := `
+  query team($org: String!, $team: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    organization(login: $org) {
+      team(slug: $team) {
         id
-        title
-        number
+        name
+        slug
       }
     }
   }`
 
 	req := graphql.NewRequest(query)
+	req.Var("org", org)
+	req.Var("team", team)
+
+	return req
+}
+
+func NewRepositoryRequest(owner, name string) *graphql.Request {
+	query := `
+  query repository($owner: String!, $name: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    repository(owner: $owner, name: $name) {
+      name
+      owner {
+        login
+      }
+      isArchived
+      isFork
+      viewerPermission
+      repositoryTopics(first: 100) {
+        nodes {
+          topic {
+            name
+          }
+        }
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(query)
+	req.Var("owner", owner)
+	req.Var("name", name)
+
+	return req
+}
+
+// projectOwnerField returns the root query field used to look up a project owner.
+// Organization and User both implement ProjectV2Owner so the rest of the query
+// is the same regardless of the owner type.
+func projectOwnerField(ownerType ProjectOwnerType) string {
+	switch ownerType {
+	case ProjectOwnerTypeOrganization:
+		return "organization"
+	case ProjectOwnerTypeUser:
+		return "user"
+	default:
+		return "repositoryOwner"
+	}
+}
+
+func NewProjectRequest(owner string, ownerType ProjectOwnerType, number int) *graphql.Request {
+	query := `
+  query project ($owner: String!, $number: Int!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    owner: %s(login: $owner) {
+      type: __typename
+      login
+      ... on ProjectV2Owner {
+        projectV2(number: $number) {
+          id
+          title
+          number
+          viewerCanUpdate
+        }
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(fmt.Sprintf(query, projectOwnerField(ownerType)))
 	req.Var("owner", owner)
 	req.Var("number", number)
 
 	return req
 }
-func NewProjectItemsRequest(owner string, number int, first int, after string) *graphql.Request {
+
+func NewProjectItemsRequest(owner string, ownerType ProjectOwnerType, number int, first int, after string) *graphql.Request {
 	query := `
   query projectPullRequests ($owner: String!, $number: Int!, $first: Int!, $after: String!) {
-    organization(login: $owner) {
-      projectV2(number: $number) {
-        id
-        title
-        number
-        items(first: $first, after: $after) {
-          totalCount
-          nodes {
-            id
-            type
-            databaseId
-            createdAt
-            updatedAt
-            isArchived
-            pullRequest: content {
-              ... on PullRequest {
-                id
-                number
-                isDraft
-                title
-                createdAt
-                updatedAt
-                author {
-                  login
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    owner: %s(login: $owner) {
+      type: __typename
+      login
+      ... on ProjectV2Owner {
+        projectV2(number: $number) {
+          id
+          title
+          number
+          items(first: $first, after: $after) {
+            totalCount
+            nodes {
+              id
+              type
+              databaseId
+              createdAt
+              updatedAt
+              isArchived
+              pullRequest: content {
+                ... on PullRequest {
+                  id
+                  number
+                  isDraft
+                  title
+                  createdAt
+                  updatedAt
+                  author {
+                    type: __typename
+                    login
+                  }
+                  repository {
+                    id
+                    owner {
+                      login
+                    }
+                    name
+                  }
+                  url
+                  state
+                  reviewDecision
+                  ...pullRequestStatus
+                  baseRefName
+                  headRefName
+                  labels(first: 100) {
+                    totalCount
+                    nodes {
+                      name
+                    }
+                  }
                 }
-                repository {
+              }
+              ...projectItemFieldValues
+              issue: content {
+                ... on Issue {
                   id
-                  owner {
+                  number
+                  title
+                  createdAt
+                  updatedAt
+                  author {
+                    type: __typename
                     login
                   }
-                  name
+                  repository {
+                    id
+                    owner {
+                      login
+                    }
+                    name
+                  }
+                  url
+                  state
                 }
-                url
-                state
               }
             }
-            issue: content {
-              ... on Issue {
+            pageInfo {
+              endCursor
+              hasNextPage
+              hasPreviousPage
+              startCursor
+            }
+          }
+        }
+      }
+    }
+  }
+` + pullRequestStatusFragment + projectItemFieldValuesFragment
+
+	req := graphql.NewRequest(fmt.Sprintf(query, projectOwnerField(ownerType)))
+	req.Var("owner", owner)
+	req.Var("number", number)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+// pullRequestStatusFragment fetches the latest review of each reviewer,
+// the pending review requests and the check state of the last commit.
+const pullRequestStatusFragment = `
+  fragment pullRequestStatus on PullRequest {
+      commits(last: 1) {
+        nodes {
+          commit {
+            statusCheckRollup {
+              state
+            }
+          }
+        }
+      }
+      latestReviews(first: 100) {
+        totalCount
+        nodes {
+          id
+          author {
+            login
+          }
+          state
+          createdAt
+        }
+      }
+      reviewRequests(first: 100) {
+        totalCount
+        nodes {
+          id
+          requestedReviewer {
+            type: __typename
+            ... on User {
+              login
+            }
+            ... on Team {
+              slug
+              organization {
+                login
+              }
+            }
+          }
+        }
+      }
+  }`
+
+const projectItemFieldValuesFragment = `
+  fragment projectItemFieldValues on ProjectV2Item {
+    fieldValues(first: 100) {
+      totalCount
+      nodes {
+        ... on ProjectV2ItemFieldTextValue {
+          text
+          field {
+            ...projectFieldCommon
+          }
+        }
+        ... on ProjectV2ItemFieldNumberValue {
+          number
+          field {
+            ...projectFieldCommon
+          }
+        }
+        ... on ProjectV2ItemFieldDateValue {
+          date
+          field {
+            ...projectFieldCommon
+          }
+        }
+        ... on ProjectV2ItemFieldSingleSelectValue {
+          name
+          optionId
+          field {
+            ...projectFieldCommon
+          }
+        }
+        ... on ProjectV2ItemFieldIterationValue {
+          title
+          iterationId
+          field {
+            ...projectFieldCommon
+          }
+        }
+      }
+    }
+  }
+
+  fragment projectFieldCommon on ProjectV2FieldConfiguration {
+    ... on ProjectV2FieldCommon {
+      id
+      name
+    }
+  }`
+
+func NewAddProjectItemRequest(projectID, contentID string) *graphql.Request {
+	mutation := `
+  mutation addProjectItem($projectId: ID!, $contentId: ID!) {
+    addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
+      item{
+        id
+        type
+        databaseId
+        createdAt
+        updatedAt
+        isArchived
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(mutation)
+	req.Var("projectId", projectID)
+	req.Var("contentId", contentID)
+
+	return req
+}
+
+func NewProjectFieldsRequest(projectID string, first int, after string) *graphql.Request {
+	query := `
+  query projectFields($projectId: ID!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    node(id: $projectId) {
+      ... on ProjectV2 {
+        id
+        title
+        number
+        fields(first: $first, after: $after) {
+          totalCount
+          nodes {
+            ... on ProjectV2FieldCommon {
+              id
+              name
+              dataType
+            }
+            ... on ProjectV2SingleSelectField {
+              options {
                 id
+                name
+              }
+            }
+            ... on ProjectV2IterationField {
+              configuration {
+                iterations {
+                  id
+                  title
+                  startDate
+                  duration
+                }
               }
             }
           }
@@ -182,19 +765,18 @@ func NewProjectItemsRequest(owner string, number int, first int, after string) *
   }`
 
 	req := graphql.NewRequest(query)
-	req.Var("owner", owner)
-	req.Var("number", number)
+	req.Var("projectId", projectID)
 	req.Var("first", first)
 	req.Var("after", after)
 
 	return req
 }
 
-func NewAddPullRequestToProjectRequest(projectId, pullRequestId string) *graphql.Request {
+func NewUpdateProjectItemFieldValueRequest(projectID, itemID, fieldID string, value ProjectFieldValue) *graphql.Request {
 	mutation := `
-  mutation addPullRequestToProject($projectId: ID!, $pullRequestId: ID!) {
-    addProjectV2ItemById(input: {projectId: $projectId, contentId: $pullRequestId}) {
-      item{
+  mutation updateProjectItemFieldValue($projectId: ID!, $itemId: ID!, $fieldId: ID!, $value: ProjectV2FieldValue!) {
+    updateProjectV2ItemFieldValue(input: {projectId: $projectId, itemId: $itemId, fieldId: $fieldId, value: $value}) {
+      projectV2Item {
         id
         type
         databaseId
@@ -206,23 +788,43 @@ func NewAddPullRequestToProjectRequest(projectId, pullRequestId string) *graphql
   }`
 
 	req := graphql.NewRequest(mutation)
-	req.Var("projectId", projectId)
-	req.Var("pullRequestId", pullRequestId)
+	req.Var("projectId", projectID)
+	req.Var("itemId", itemID)
+	req.Var("fieldId", fieldID)
+	req.Var("value", value)
 
 	return req
 }
 
-func NewDeletePullRequestFromProjectRequest(projectId, pullRequestId string) *graphql.Request {
+func NewDeleteProjectItemRequest(projectID, itemID string) *graphql.Request {
 	mutation := `
-  mutation deletePullRequestFromProject($projectId: ID!, $pullRequestId: ID!) {
-    deleteProjectV2Item(input: {projectId: $projectId, itemId: $pullRequestId}) {
+  mutation deleteProjectItem($projectId: ID!, $itemId: ID!) {
+    deleteProjectV2Item(input: {projectId: $projectId, itemId: $itemId}) {
       deletedItemId
     }
   }`
 
 	req := graphql.NewRequest(mutation)
-	req.Var("projectId", projectId)
-	req.Var("pullRequestId", pullRequestId)
+	req.Var("projectId", projectID)
+	req.Var("itemId", itemID)
+
+	return req
+}
+
+func NewArchiveProjectItemRequest(projectID, itemID string) *graphql.Request {
+	mutation := `
+  mutation archiveProjectItem($projectId: ID!, $itemId: ID!) {
+    archiveProjectV2Item(input: {projectId: $projectId, itemId: $itemId}) {
+      item {
+        id
+        isArchived
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(mutation)
+	req.Var("projectId", projectID)
+	req.Var("itemId", itemID)
 
 	return req
 }
@@ -236,10 +838,10 @@ func NewViewerQuery() string {
   }`
 }
 
-func NewAddAssigneeToPullRequestRequest(pullRequestID, userID string) *graphql.Request {
+func NewAddAssigneeRequest(assignableID, userID string) *graphql.Request {
 	mutation := `
-  mutation addAssigneeToPullRequest($pullRequestId: ID!, $userId: ID!) {
-    addAssigneesToAssignable(input: {assignableId: $pullRequestId, assigneeIds: [$userId]}) {
+  mutation addAssignee($assignableId: ID!, $userId: ID!) {
+    addAssigneesToAssignable(input: {assignableId: $assignableId, assigneeIds: [$userId]}) {
       assignable {
         assignees(first: 100) {
           totalCount
@@ -252,7 +854,7 @@ func NewAddAssigneeToPullRequestRequest(pullRequestID, userID string) *graphql.R
   }`
 
 	req := graphql.NewRequest(mutation)
-	req.Var("pullRequestId", pullRequestID)
+	req.Var("assignableId", assignableID)
 	req.Var("userId", userID)
 
 	return req
@@ -261,6 +863,11 @@ func NewAddAssigneeToPullRequestRequest(pullRequestID, userID string) *graphql.R
 func NewLookupUserRequest(login string) *graphql.Request {
 	query := `
   query user($login: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
     user(login: $login) {
       id
       login
@@ -274,22 +881,95 @@ func NewLookupUserRequest(login string) *graphql.Request {
 	return req
 }
 
-func NewLookupUserMembershipRequest(login string) *graphql.Request {
+func NewLookupUserMembershipRequest(login string, first int, after string) *graphql.Request {
 	query := `
-  query user($login: String!) {
+  query user($login: String!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
     user(login: $login) {
-      organizations(first: 100, after: "") {
+      organizations(first: $first, after: $after) {
         totalCount
         nodes {
           login
           name
         }
+        pageInfo {
+          endCursor
+          hasNextPage
+          hasPreviousPage
+          startCursor
+        }
       }
     }
   }`
 
 	req := graphql.NewRequest(query)
 	req.Var("login", login)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+// NewAssigneesRequest fetches a page of assignees of a pull request or an issue.
+func NewAssigneesRequest(assignableID string, first int, after string) *graphql.Request {
+	query := `
+  query assignees($id: ID!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    node(id: $id) {
+      ... on Assignable {
+        assignees(first: $first, after: $after) {
+          totalCount
+          nodes {
+            login
+          }
+          pageInfo {
+            endCursor
+            hasNextPage
+          }
+        }
+      }
+    }
+  }`
+
+	req := graphql.NewRequest(query)
+	req.Var("id", assignableID)
+	req.Var("first", first)
+	req.Var("after", after)
+
+	return req
+}
+
+// NewPullRequestProjectsRequest fetches a page of projects of a pull request.
+func NewPullRequestProjectsRequest(prID string, first int, after string) *graphql.Request {
+	query := `
+  query pullRequestProjects($id: ID!, $first: Int!, $after: String!) {
+    rateLimit {
+      cost
+      remaining
+      resetAt
+    }
+    node(id: $id) {
+      ... on PullRequest {
+        projects: projectsV2(first: $first, after: $after) {
+          ...pullRequestProjects
+        }
+      }
+    }
+  }
+` + pullRequestProjectsFragment
+
+	req := graphql.NewRequest(query)
+	req.Var("id", prID)
+	req.Var("first", first)
+	req.Var("after", after)
 
 	return req
 }
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a request is attempted by default.
+	DefaultMaxAttempts = 3
+
+	retryBaseWait = time.Second
+	retryMaxWait  = 30 * time.Second
+)
+
+// ServerError is returned when GitHub responds with a server error.
+type ServerError struct {
+	StatusCode int
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server error: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// serverErrorTransport turns server error responses into ServerErrors
+// which the GraphQL client would otherwise either hide or report as a decoding error.
+type serverErrorTransport struct {
+	base http.RoundTripper
+}
+
+func (t *serverErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return nil, &ServerError{StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+// retrier retries requests that failed because of transient server or network errors
+// with exponential backoff and full jitter.
+type retrier struct {
+	maxAttempts int
+	notify      func(attempt int, wait time.Duration, err error)
+}
+
+// wait waits before the next attempt if the error is transient
+// and reports whether the request should be retried.
+func (r *retrier) wait(ctx context.Context, err error, attempt int) bool {
+	maxAttempts := r.maxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if attempt >= maxAttempts || ctx.Err() != nil || !isTransient(err) {
+		return false
+	}
+
+	wait := rand.N(min(retryBaseWait<<(attempt-1), retryMaxWait)) + 1
+
+	if r.notify != nil {
+		r.notify(attempt, wait, err)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isTransient reports whether the request may succeed if retried.
+func isTransient(err error) bool {
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"server error", fmt.Errorf("request failed: %w", &ServerError{StatusCode: http.StatusBadGateway}), true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, true},
+		{"rate limited", &RateLimitError{StatusCode: http.StatusForbidden}, false},
+		{"graphql error", errors.New("graphql: Could not resolve to a node"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if want, got := tt.transient, isTransient(tt.err); want != got {
+				t.Fatalf("Expected %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"user":{"id":"U1","login":"user"}}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+	var retries int
+	client.OnRetry(func(attempt int, wait time.Duration, err error) {
+		retries++
+		if want, got := retryBaseWait, wait; got > want {
+			t.Fatalf("Expected a wait up to %s, got %s", want, got)
+		}
+	})
+
+	user, err := client.LookupUser(context.Background(), "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "U1", user.ID; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 2, calls; want != got {
+		t.Fatalf("Expected %d calls, got %d", want, got)
+	}
+	if want, got := 1, retries; want != got {
+		t.Fatalf("Expected %d retries, got %d", want, got)
+	}
+}
+
+func TestClientMaxAttempts(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+	client.SetMaxAttempts(1)
+
+	_, err := client.LookupUser(context.Background(), "user")
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("Expected a server error, got %v", err)
+	}
+	if want, got := 1, calls; want != got {
+		t.Fatalf("Expected %d calls, got %d", want, got)
+	}
+}
+
+func TestClientDoesNotRetryDelete(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client(), server.URL)
+
+	if err := client.DeleteProjectItem(context.Background(), "project", "item"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if want, got := 1, calls; want != got {
+		t.Fatalf("Expected %d calls, got %d", want, got)
+	}
+}
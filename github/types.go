@@ -1,6 +1,7 @@
 package github
 
 import (
+	"slices"
 	"strings"
 	"time"
 )
@@ -18,6 +19,12 @@ type PageInfo struct {
 	StartCursor     string `json:"startCursor"`
 }
 
+type UserConnection struct {
+	TotalCount int      `json:"totalCount"`
+	Nodes      []User   `json:"nodes"`
+	PageInfo   PageInfo `json:"pageInfo"`
+}
+
 type User struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
@@ -37,20 +44,128 @@ type Author struct {
 	Type  AuthorType `json:"type"`
 }
 
+type Label struct {
+	Name string `json:"name"`
+}
+
+type ReviewerType string
+
+const (
+	ReviewerTypeTeam ReviewerType = "Team"
+	ReviewerTypeUser ReviewerType = "User"
+)
+
+// RequestedReviewer is either a user or a team.
+type RequestedReviewer struct {
+	Type         ReviewerType `json:"type"`
+	Login        string       `json:"login"` // User.
+	Slug         string       `json:"slug"`  // Team.
+	Organization struct {
+		Login string `json:"login"`
+	} `json:"organization"` // Team.
+}
+
 type ReviewRequest struct {
-	ID                string `json:"id"`
-	RequestedReviewer User   `json:"requestedReviewer"`
+	ID                string            `json:"id"`
+	RequestedReviewer RequestedReviewer `json:"requestedReviewer"`
+}
+
+// CheckState is the combined state of the checks and commit statuses of a commit.
+type CheckState string
+
+const (
+	CheckStateError    CheckState = "ERROR"
+	CheckStateExpected CheckState = "EXPECTED"
+	CheckStateFailure  CheckState = "FAILURE"
+	CheckStatePending  CheckState = "PENDING"
+	CheckStateSuccess  CheckState = "SUCCESS"
+)
+
+func (s CheckState) IsValid() bool {
+	switch s {
+	case CheckStateError, CheckStateExpected, CheckStateFailure, CheckStatePending, CheckStateSuccess:
+		return true
+	}
+	return false
+}
+
+type StatusCheckRollup struct {
+	State CheckState `json:"state"`
+}
+
+type Commit struct {
+	StatusCheckRollup *StatusCheckRollup `json:"statusCheckRollup"`
+}
+
+type PullRequestCommit struct {
+	Commit Commit `json:"commit"`
+}
+
+type ReviewState string
+
+const (
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateCommented        ReviewState = "COMMENTED"
+	ReviewStateDismissed        ReviewState = "DISMISSED"
+	ReviewStatePending          ReviewState = "PENDING"
+)
+
+func (s ReviewState) IsValid() bool {
+	switch s {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented, ReviewStateDismissed, ReviewStatePending:
+		return true
+	}
+	return false
 }
 
 type Review struct {
-	ID        string `json:"id"`
-	Author    User   `json:"author"`
-	State     string `json:"state"`
-	CreatedAt string `json:"createdAt"`
+	ID        string      `json:"id"`
+	Author    User        `json:"author"`
+	State     ReviewState `json:"state"`
+	CreatedAt string      `json:"createdAt"`
 }
 
+type IssueState string
+
+func (s IssueState) IsValid() bool {
+	switch s {
+	case IssueStateClosed, IssueStateOpen:
+		return true
+	}
+	return false
+}
+
+const (
+	IssueStateClosed IssueState = "CLOSED"
+	IssueStateOpen   IssueState = "OPEN"
+)
+
 type Issue struct {
-	ID string `json:"id"`
+	ID            string         `json:"id"`
+	Number        int            `json:"number"`
+	Title         string         `json:"title"`
+	Author        Author         `json:"author"`
+	Repository    Repository     `json:"repository"`
+	URL           string         `json:"url"`
+	State         IssueState     `json:"state"`
+	Assignees     UserConnection `json:"assignees"`
+	ProjectItemID string         `json:"projectItemId"`
+	ProjectItem   *ProjectItem   `json:"-"`
+}
+
+// IsArchived reports whether the issue's project item is archived.
+func (i *Issue) IsArchived() bool {
+	return i.ProjectItem != nil && i.ProjectItem.IsArchived
+}
+
+func (i *Issue) IsAuthorAssigned() bool {
+	for _, a := range i.Assignees.Nodes {
+		if a.Login == i.Author.Login {
+			return true
+		}
+	}
+	return false
 }
 
 type ProjectItem struct {
@@ -62,20 +177,139 @@ type ProjectItem struct {
 	IsArchived  bool         `json:"isArchived"`
 	PullRequest *PullRequest `json:"pullRequest"`
 	Issue       *Issue       `json:"issue"`
+	Project     *Project     `json:"project"`
+	FieldValues struct {
+		TotalCount int                     `json:"totalCount"`
+		Nodes      []ProjectItemFieldValue `json:"nodes"`
+		PageInfo   PageInfo                `json:"pageInfo"`
+	} `json:"fieldValues"`
+}
+
+// FieldValue returns the value of the field if it's set.
+func (i *ProjectItem) FieldValue(fieldID string) (ProjectItemFieldValue, bool) {
+	for _, v := range i.FieldValues.Nodes {
+		if v.Field.ID == fieldID {
+			return v, true
+		}
+	}
+	return ProjectItemFieldValue{}, false
+}
+
+// ProjectItemFieldValue is a value of a project item field.
+// Only the fields corresponding to the field data type are set.
+type ProjectItemFieldValue struct {
+	Field struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"field"`
+	Text        string   `json:"text"`
+	Number      *float64 `json:"number"`
+	Date        string   `json:"date"`
+	Name        string   `json:"name"` // Single select option name.
+	OptionID    string   `json:"optionId"`
+	Title       string   `json:"title"` // Iteration title.
+	IterationID string   `json:"iterationId"`
+}
+
+// Equal reports whether the item field value is the same as value.
+func (v ProjectItemFieldValue) Equal(value ProjectFieldValue) bool {
+	switch {
+	case value.Text != nil:
+		return v.Text == *value.Text
+	case value.Number != nil:
+		return v.Number != nil && *v.Number == *value.Number
+	case value.Date != nil:
+		return v.Date == *value.Date
+	case value.SingleSelectOptionID != nil:
+		return v.OptionID == *value.SingleSelectOptionID
+	case value.IterationID != nil:
+		return v.IterationID == *value.IterationID
+	}
+	return false
+}
+
+type ProjectOwnerType string
+
+const (
+	// ProjectOwnerTypeAny resolves the project owner as either an organization or a user.
+	ProjectOwnerTypeAny          ProjectOwnerType = ""
+	ProjectOwnerTypeOrganization ProjectOwnerType = "Organization"
+	ProjectOwnerTypeUser         ProjectOwnerType = "User"
+)
+
+type ProjectOwner struct {
+	Type    ProjectOwnerType `json:"type"`
+	Login   string           `json:"login"`
+	Project *Project         `json:"projectV2"`
+}
+
+type ProjectConnection struct {
+	TotalCount int       `json:"totalCount"`
+	Nodes      []Project `json:"nodes"`
+	PageInfo   PageInfo  `json:"pageInfo"`
 }
 
 type Project struct {
-	ID     string `json:"id"`
-	Number int    `json:"number"`
-	Title  string `json:"title"`
-	Owner  struct {
-		Login string `json:"login"`
+	ID              string `json:"id"`
+	Number          int    `json:"number"`
+	Title           string `json:"title"`
+	ViewerCanUpdate bool   `json:"viewerCanUpdate"`
+	Owner           struct {
+		Type  ProjectOwnerType `json:"type"`
+		Login string           `json:"login"`
 	} `json:"owner"`
 	Items struct {
 		TotalCount int           `json:"totalCount"`
 		Nodes      []ProjectItem `json:"nodes"`
 		PageInfo   PageInfo      `json:"pageInfo"`
 	} `json:"items"`
+	Fields struct {
+		TotalCount int            `json:"totalCount"`
+		Nodes      []ProjectField `json:"nodes"`
+		PageInfo   PageInfo       `json:"pageInfo"`
+	} `json:"fields"`
+}
+
+type ProjectFieldType string
+
+const (
+	ProjectFieldTypeDate         ProjectFieldType = "DATE"
+	ProjectFieldTypeIteration    ProjectFieldType = "ITERATION"
+	ProjectFieldTypeNumber       ProjectFieldType = "NUMBER"
+	ProjectFieldTypeSingleSelect ProjectFieldType = "SINGLE_SELECT"
+	ProjectFieldTypeText         ProjectFieldType = "TEXT"
+)
+
+type ProjectFieldOption struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ProjectIteration struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	StartDate string `json:"startDate"`
+	Duration  int    `json:"duration"` // In days.
+}
+
+type ProjectField struct {
+	ID            string               `json:"id"`
+	Name          string               `json:"name"`
+	DataType      ProjectFieldType     `json:"dataType"`
+	Options       []ProjectFieldOption `json:"options"`
+	Configuration struct {
+		Iterations []ProjectIteration `json:"iterations"`
+	} `json:"configuration"`
+}
+
+// ProjectFieldValue is a value of a project field.
+// Only one of the fields should be set according to the field data type.
+type ProjectFieldValue struct {
+	Text                 *string  `json:"text,omitempty"`
+	Number               *float64 `json:"number,omitempty"`
+	Date                 *string  `json:"date,omitempty"`
+	SingleSelectOptionID *string  `json:"singleSelectOptionId,omitempty"`
+	IterationID          *string  `json:"iterationId,omitempty"`
 }
 
 type PullRequestState string
@@ -94,26 +328,112 @@ const (
 	PullRequestStateOpen   PullRequestState = "OPEN"
 )
 
+type ReviewDecision string
+
+func (d ReviewDecision) IsValid() bool {
+	switch d {
+	case ReviewDecisionApproved, ReviewDecisionChangesRequested, ReviewDecisionReviewRequired:
+		return true
+	}
+	return false
+}
+
+const (
+	ReviewDecisionApproved         ReviewDecision = "APPROVED"
+	ReviewDecisionChangesRequested ReviewDecision = "CHANGES_REQUESTED"
+	ReviewDecisionReviewRequired   ReviewDecision = "REVIEW_REQUIRED"
+)
+
 type PullRequest struct {
-	ID         string           `json:"id"`
-	Number     int              `json:"number"`
-	Title      string           `json:"title"`
-	IsDraft    bool             `json:"isDraft"`
-	Author     Author           `json:"author"`
-	Repository Repository       `json:"repository"`
-	URL        string           `json:"url"`
-	State      PullRequestState `json:"state"`
-	Assignees  struct {
+	ID             string           `json:"id"`
+	Number         int              `json:"number"`
+	Title          string           `json:"title"`
+	IsDraft        bool             `json:"isDraft"`
+	Author         Author           `json:"author"`
+	Repository     Repository       `json:"repository"`
+	URL            string           `json:"url"`
+	State          PullRequestState `json:"state"`
+	ReviewDecision ReviewDecision   `json:"reviewDecision"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+	BaseRefName    string           `json:"baseRefName"`
+	HeadRefName    string           `json:"headRefName"`
+	LatestReviews  struct {
 		TotalCount int      `json:"totalCount"`
-		Nodes      []User   `json:"nodes"`
+		Nodes      []Review `json:"nodes"`
+		PageInfo   PageInfo `json:"pageInfo"`
+	} `json:"latestReviews"`
+	ReviewRequests struct {
+		TotalCount int             `json:"totalCount"`
+		Nodes      []ReviewRequest `json:"nodes"`
+		PageInfo   PageInfo        `json:"pageInfo"`
+	} `json:"reviewRequests"`
+	Labels struct {
+		TotalCount int      `json:"totalCount"`
+		Nodes      []Label  `json:"nodes"`
 		PageInfo   PageInfo `json:"pageInfo"`
-	} `json:"assignees"`
-	Projects struct {
-		TotalCount int       `json:"totalCount"`
-		Nodes      []Project `json:"nodes"`
-		PageInfo   PageInfo  `json:"pageInfo"`
-	} `json:"projects"`
-	ProjectItemID string `json:"projectItemId"`
+	} `json:"labels"`
+	Commits struct {
+		Nodes []PullRequestCommit `json:"nodes"`
+	} `json:"commits"` // Only the last commit.
+	Assignees    UserConnection    `json:"assignees"`
+	Projects     ProjectConnection `json:"projects"`
+	ProjectItems struct {
+		TotalCount int           `json:"totalCount"`
+		Nodes      []ProjectItem `json:"nodes"`
+		PageInfo   PageInfo      `json:"pageInfo"`
+	} `json:"projectItems"`
+	ProjectItemID string       `json:"projectItemId"`
+	ProjectItem   *ProjectItem `json:"-"`
+}
+
+// IsReviewRequested reports whether the pull request has pending review requests.
+func (r *PullRequest) IsReviewRequested() bool {
+	return len(r.ReviewRequests.Nodes) > 0
+}
+
+// HasReview reports whether any reviewer's latest review is in one of the states.
+func (r *PullRequest) HasReview(states ...ReviewState) bool {
+	for _, review := range r.LatestReviews.Nodes {
+		if slices.Contains(states, review.State) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckState returns the combined check state of the last commit
+// or an empty string if there are no checks.
+func (r *PullRequest) CheckState() CheckState {
+	if len(r.Commits.Nodes) == 0 || r.Commits.Nodes[0].Commit.StatusCheckRollup == nil {
+		return ""
+	}
+	return r.Commits.Nodes[0].Commit.StatusCheckRollup.State
+}
+
+// LabelNames returns the names of the pull request's labels.
+func (r *PullRequest) LabelNames() []string {
+	names := make([]string, 0, len(r.Labels.Nodes))
+	for _, label := range r.Labels.Nodes {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// FindProjectItem returns the pull request's item in the project if any.
+// It relies on ProjectItems which are only fetched for a single pull request.
+func (r *PullRequest) FindProjectItem(projectID string) *ProjectItem {
+	for i, item := range r.ProjectItems.Nodes {
+		if item.Project != nil && item.Project.ID == projectID {
+			return &r.ProjectItems.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// IsArchived reports whether the pull request's project item is archived.
+func (r *PullRequest) IsArchived() bool {
+	return r.ProjectItem != nil && r.ProjectItem.IsArchived
 }
 
 func (r *PullRequest) IsAuthorAssigned() bool {
@@ -130,13 +450,37 @@ type RepositoryOwner struct {
 }
 
 type Repository struct {
-	Name         string          `json:"name"`
-	Owner        RepositoryOwner `json:"owner"`
-	PullRequests struct {
+	Name             string          `json:"name"`
+	Owner            RepositoryOwner `json:"owner"`
+	IsArchived       bool            `json:"isArchived"`
+	IsFork           bool            `json:"isFork"`
+	ViewerPermission string          `json:"viewerPermission"` // ADMIN, MAINTAIN, WRITE, TRIAGE or READ.
+	PullRequests     struct {
 		TotalCount int           `json:"totalCount"`
 		Nodes      []PullRequest `json:"nodes"`
 		PageInfo   PageInfo      `json:"pageInfo"`
 	} `json:"pullRequests"`
+	Issues struct {
+		TotalCount int      `json:"totalCount"`
+		Nodes      []Issue  `json:"nodes"`
+		PageInfo   PageInfo `json:"pageInfo"`
+	} `json:"issues"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"` // Only fetched for a single repository.
+}
+
+// TopicNames returns the names of the repository's topics.
+func (r *Repository) TopicNames() []string {
+	names := make([]string, 0, len(r.RepositoryTopics.Nodes))
+	for _, node := range r.RepositoryTopics.Nodes {
+		names = append(names, node.Topic.Name)
+	}
+	return names
 }
 
 type Error struct {
@@ -159,19 +503,78 @@ func (e Errors) Error() string {
 	return strings.TrimSuffix(msg, "\n")
 }
 
+type RepositoryConnection struct {
+	TotalCount int          `json:"totalCount"`
+	Nodes      []Repository `json:"nodes"`
+	PageInfo   PageInfo     `json:"pageInfo"`
+}
+
+type OwnerRepositoriesResponse struct {
+	Owner *struct {
+		Repositories RepositoryConnection `json:"repositories"`
+	} `json:"owner"`
+	Errors Errors `json:"errors"`
+}
+
+type TeamRepositoriesResponse struct {
+	Organization *Organization `json:"organization"`
+	Errors       Errors        `json:"errors"`
+}
+
+type SearchRepositoriesResponse struct {
+	Search RepositoryConnection `json:"search"`
+	Errors Errors               `json:"errors"`
+}
+
 type PullRequestResponse struct {
 	Repository *Repository `json:"repository"`
 	Errors     Errors      `json:"errors"`
 }
 
+type SinglePullRequestResponse struct {
+	Repository *struct {
+		PullRequest *PullRequest `json:"pullRequest"`
+	} `json:"repository"`
+	Errors Errors `json:"errors"`
+}
+
+type IssueResponse struct {
+	Repository *Repository `json:"repository"`
+	Errors     Errors      `json:"errors"`
+}
+
+// TeamMembership defines whether members of child teams are team members.
+type TeamMembership string
+
+const (
+	TeamMembershipAll       TeamMembership = "ALL"       // Immediate and child team members.
+	TeamMembershipImmediate TeamMembership = "IMMEDIATE" // Only immediate team members.
+)
+
+func (m TeamMembership) IsValid() bool {
+	switch m {
+	case TeamMembershipAll, TeamMembershipImmediate:
+		return true
+	}
+	return false
+}
+
 type Team struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
+	Slug    string `json:"slug"`
 	Members struct {
 		TotalCount int      `json:"totalCount"`
 		Nodes      []User   `json:"nodes"`
 		PageInfo   PageInfo `json:"pageInfo"`
 	} `json:"members"`
+	ChildTeams   TeamConnection       `json:"childTeams"`
+	Repositories RepositoryConnection `json:"repositories"`
+}
+
+type TeamConnection struct {
+	Nodes    []Team   `json:"nodes"`
+	PageInfo PageInfo `json:"pageInfo"`
 }
 
 type Organization struct {
@@ -187,31 +590,65 @@ type TeamMembersResponse struct {
 	Errors       Errors        `json:"errors"`
 }
 
-type ProjectResponse struct {
+type ChildTeamsResponse struct {
 	Organization *Organization `json:"organization"`
 	Errors       Errors        `json:"errors"`
 }
 
-type ProjectItemsResponse struct {
+type TeamResponse struct {
 	Organization *Organization `json:"organization"`
 	Errors       Errors        `json:"errors"`
 }
 
-type AddPullRequestToProjectResponse struct {
+type RepositoryResponse struct {
+	Repository *Repository `json:"repository"`
+	Errors     Errors      `json:"errors"`
+}
+
+type ProjectResponse struct {
+	Owner  *ProjectOwner `json:"owner"`
+	Errors Errors        `json:"errors"`
+}
+
+type ProjectItemsResponse struct {
+	Owner  *ProjectOwner `json:"owner"`
+	Errors Errors        `json:"errors"`
+}
+
+type AddProjectItemResponse struct {
 	AddProjectV2ItemByID struct {
 		Item *ProjectItem `json:"item"`
 	} `json:"addProjectV2ItemById"`
 	Errors Errors `json:"errors"`
 }
 
-type DeletePullRequestFromProjectResponse struct {
+type ProjectFieldsResponse struct {
+	Node   *Project `json:"node"`
+	Errors Errors   `json:"errors"`
+}
+
+type UpdateProjectItemFieldValueResponse struct {
+	UpdateProjectV2ItemFieldValue struct {
+		Item *ProjectItem `json:"projectV2Item"`
+	} `json:"updateProjectV2ItemFieldValue"`
+	Errors Errors `json:"errors"`
+}
+
+type DeleteProjectItemResponse struct {
 	DeleteProjectV2Item struct {
 		DeletedItemId string `json:"deletedItemId"`
 	} `json:"deleteProjectV2Item"`
 	Errors Errors `json:"errors"`
 }
 
-type AddAssigneeToPullRequestResponse struct {
+type ArchiveProjectItemResponse struct {
+	ArchiveProjectV2Item struct {
+		Item *ProjectItem `json:"item"`
+	} `json:"archiveProjectV2Item"`
+	Errors Errors `json:"errors"`
+}
+
+type AddAssigneeResponse struct {
 	AddAssigneesToAssignable struct {
 		Assignable struct {
 			Assignees struct {
@@ -243,11 +680,26 @@ type LookupUserResponse struct {
 }
 
 type LookupUserMembershipResponse struct {
-	User struct {
+	User *struct {
 		Organizations struct {
 			TotalCount int            `json:"totalCount"`
 			Nodes      []Organization `json:"nodes"`
+			PageInfo   PageInfo       `json:"pageInfo"`
 		} `json:"organizations"`
 	} `json:"user"`
 	Errors Errors `json:"errors"`
 }
+
+type AssigneesResponse struct {
+	Node *struct {
+		Assignees UserConnection `json:"assignees"`
+	} `json:"node"`
+	Errors Errors `json:"errors"`
+}
+
+type PullRequestProjectsResponse struct {
+	Node *struct {
+		Projects ProjectConnection `json:"projects"`
+	} `json:"node"`
+	Errors Errors `json:"errors"`
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// addNewIssues adds new issues to the project
+// based on the author and state of the issue
+// and sets the field values of the newly added project items.
+func addNewIssues(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	project *github.Project,
+	projectIssues map[prKey]*github.Issue,
+	fields []fieldValue,
+) (int, error) {
+	var addCount int
+	fmt.Fprintln(cfg.out(), "Checking for issues to add:")
+	for _, repository := range job.repos {
+		fmt.Fprintf(cfg.out(), "  - %s/%s\n", repository.owner, repository.name)
+		for issue, err := range getAuthorsIssues(ctx, client, cfg, job, authors, repository.owner, repository.name) {
+			if err != nil {
+				return addCount, fmt.Errorf("error fetching authors' issues: %w", err)
+			}
+
+			key := prKey{owner: issue.Repository.Owner.Login, repo: issue.Repository.Name, number: issue.Number}
+			if _, ok := projectIssues[key]; ok {
+				if cfg.verbose {
+					fmt.Fprintf(cfg.out(), "    - %s %s %s %s EXISTS\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+				}
+				cfg.report.issue(job, "add", issue, decisionExists, "already in the project", "", nil)
+				continue
+			}
+
+			if cfg.verbose {
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s NEW\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+			} else {
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+			}
+
+			if err := addProjectItem(ctx, client, cfg, authors, project, issueContent(issue), job.issues.add.assignAuthor, fields); err != nil {
+				cfg.report.issue(job, "add", issue, decisionAdd, "", "", err)
+				return addCount, err
+			}
+			cfg.report.issue(job, "add", issue, decisionAdd, "", actionResult(cfg), nil)
+			addCount++
+		}
+	}
+
+	if addCount > 0 {
+		fmt.Fprintf(cfg.out(), "Added %d issues\n", addCount)
+	} else {
+		fmt.Fprintln(cfg.out(), "No issues to add")
+	}
+
+	return addCount, nil
+}
+
+// deleteCompletedIssues deletes or archives issues from the project that match the state.
+// It takes authors into consideration if job.issues.delete.allAuthors is false.
+func deleteCompletedIssues(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	project *github.Project,
+	projectIssues map[prKey]*github.Issue,
+) (int, error) {
+	if len(job.issues.delete.states) == 0 {
+		return 0, nil // Nothing else to do.
+	}
+
+	archive := job.issues.delete.mode == deleteModeArchive
+	action, actionPast := decisionDelete, "Deleted"
+	if archive {
+		action, actionPast = decisionArchive, "Archived"
+	}
+	reason := fmt.Sprintf("state is one of %v", job.issues.delete.states)
+
+	fmt.Fprintf(cfg.out(), "Checking for issues to %s:\n", action)
+
+	var deleteCount int
+	for _, issue := range projectIssues {
+		// Already archived items don't need to be archived again.
+		if archive && issue.IsArchived() {
+			if cfg.verbose {
+				fmt.Fprintf(cfg.out(), "  - %s %s %s %s ARCHIVED\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+			}
+			cfg.report.issue(job, "delete", issue, decisionKeep, reasonAlreadyArchived, "", nil)
+			continue
+		}
+
+		if !job.issues.delete.allAuthors {
+			ourAuthor, err := authors.Resolve(ctx, issue.Author.Login)
+			if err != nil {
+				return deleteCount, fmt.Errorf("error checking if %s is our author: %w", issue.Author.Login, err)
+			}
+
+			if !ourAuthor {
+				if cfg.verbose {
+					fmt.Fprintf(cfg.out(), "  - %s %s %s %s SKIP\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+				}
+				cfg.report.issue(job, "delete", issue, decisionSkip, reasonAuthorNotIncluded, "", nil)
+				continue
+			}
+		}
+
+		if !slices.Contains(job.issues.delete.states, issue.State) {
+			if cfg.verbose {
+				fmt.Fprintf(cfg.out(), "  - %s %s %s %s KEEP\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+			}
+			cfg.report.issue(job, "delete", issue, decisionKeep, "", "", nil)
+			continue
+		}
+
+		deleteCount++
+
+		if cfg.verbose {
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s\n", issue.URL, issue.Author.Login, issue.Title, issue.State, strings.ToUpper(action))
+		} else {
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+		}
+
+		if err := removeProjectItem(ctx, client, cfg, project, issueContent(issue), archive); err != nil {
+			cfg.report.issue(job, "delete", issue, action, reason, "", err)
+			return deleteCount, err
+		}
+		cfg.report.issue(job, "delete", issue, action, reason, actionResult(cfg), nil)
+	}
+
+	if deleteCount > 0 {
+		fmt.Fprintf(cfg.out(), "%s %d issues\n", actionPast, deleteCount)
+	} else {
+		fmt.Fprintf(cfg.out(), "No issues to %s\n", action)
+	}
+
+	return deleteCount, nil
+}
+
+// getProjectIssues returns all issues of the project.
+func getProjectIssues(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+) (map[prKey]*github.Issue, error) {
+	projectIssues := make(map[prKey]*github.Issue)
+
+	if cfg.verbose {
+		fmt.Fprintln(cfg.out(), "Fetching project issues")
+	}
+
+	for issue, err := range client.GetProjectIssues(ctx, job.project.owner, job.project.ownerType, job.project.number) {
+		if err != nil {
+			return nil, fmt.Errorf("error fetching project issues: %w", err)
+		}
+
+		key := prKey{owner: issue.Repository.Owner.Login, repo: issue.Repository.Name, number: issue.Number}
+		projectIssues[key] = issue
+	}
+
+	if cfg.verbose {
+		keys := slices.Collect(maps.Keys(projectIssues))
+		slices.SortFunc(keys, comparePRKeys)
+
+		var repo string
+		for _, key := range keys {
+			currentRepo := key.owner + "/" + key.repo
+			if repo != currentRepo {
+				repo = currentRepo
+				fmt.Fprintf(cfg.out(), "  - %s\n", repo)
+			}
+
+			issue := projectIssues[key]
+			if issue.IsArchived() {
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s ARCHIVED\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+				continue
+			}
+			fmt.Fprintf(cfg.out(), "    - %s %s %s %s\n", issue.URL, issue.Author.Login, issue.Title, issue.State)
+		}
+	}
+
+	return projectIssues, nil
+}
+
+// getAuthorsIssues returns an iterator that yields issues
+// for a repository filtered according to the authors.
+// Filtering by the state is done by client.GetRepositoryIssues.
+func getAuthorsIssues(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	owner string,
+	repo string,
+) iter.Seq2[*github.Issue, error] {
+	return func(yield func(*github.Issue, error) bool) {
+		for issue, err := range client.GetRepositoryIssues(ctx, owner, repo, job.issues.add.states) {
+			if err != nil {
+				yield(nil, fmt.Errorf("error fetching repository issues: %w", err))
+				return
+			}
+
+			// Skip issues from non-users (e.g. bots).
+			if issue.Author.Type != github.AuthorTypeUser {
+				cfg.report.issue(job, "add", issue, decisionSkip, "failed: author is a user", "", nil)
+				continue
+			}
+
+			includedAuthor, err := authors.Resolve(ctx, issue.Author.Login)
+			if err != nil {
+				yield(nil, fmt.Errorf("error evaluating author filter for %s: %w", issue.Author.Login, err))
+				return
+			}
+
+			if !includedAuthor {
+				cfg.report.issue(job, "add", issue, decisionSkip, reasonAuthorNotIncluded, "", nil)
+				continue
+			}
+
+			if !yield(issue, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestAddNewIssues(t *testing.T) {
+	ctx := context.Background()
+
+	newIssue := func(number int, login string, authorType github.AuthorType) *github.Issue {
+		issue := &github.Issue{ID: login + "-issue", Number: number, State: github.IssueStateOpen}
+		issue.Author.Login = login
+		issue.Author.Type = authorType
+		issue.Repository.Owner.Login = "org"
+		issue.Repository.Name = "repo"
+		return issue
+	}
+
+	var added []string
+	client := &fakeGithubClient{
+		GetRepositoryIssuesFunc: func(ctx context.Context, owner, name string, states []github.IssueState) iter.Seq2[*github.Issue, error] {
+			if want, got := []github.IssueState{github.IssueStateOpen}, states; !slices.Equal(want, got) {
+				t.Errorf("Expected states %v, got %v", want, got)
+			}
+			return func(yield func(*github.Issue, error) bool) {
+				for _, issue := range []*github.Issue{
+					newIssue(1, "user1", github.AuthorTypeUser),
+					newIssue(2, "bot", github.AuthorTypeBot),
+					newIssue(3, "user2", github.AuthorTypeUser),
+					newIssue(4, "user3", github.AuthorTypeUser),
+				} {
+					if !yield(issue, nil) {
+						return
+					}
+				}
+			}
+		},
+		AddIssueToProjectFunc: func(ctx context.Context, projectID, issueID string) (string, error) {
+			added = append(added, issueID)
+			return "item", nil
+		},
+	}
+
+	job := configJob{repos: []configRepo{{"org", "repo"}}}
+	job.authors.exclude.users = []string{"user2"}
+	job.issues.enabled = true
+	job.issues.add.states = []github.IssueState{github.IssueStateOpen}
+
+	authors, err := NewAuthors(ctx, newMemberships(client, false), job.authors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projectIssues := map[prKey]*github.Issue{
+		{"org", "repo", 4}: newIssue(4, "user3", github.AuthorTypeUser),
+	}
+
+	count, err := addNewIssues(ctx, client, config{}, job, authors, &github.Project{ID: "project"}, projectIssues, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 1, count; want != got {
+		t.Fatalf("Expected %d added, got %d", want, got)
+	}
+	if want, got := []string{"user1-issue"}, added; !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
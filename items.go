@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// Kinds of project item content.
+const (
+	contentPullRequest = "PR"
+	contentIssue       = "issue"
+)
+
+// projectContent is a pull request or an issue that is or is about to become a project item.
+type projectContent struct {
+	kind           string // contentPullRequest or contentIssue.
+	id             string
+	url            string
+	author         string
+	authorAssigned bool
+	itemID         string // Empty if the content isn't in the project yet.
+}
+
+func pullRequestContent(pr *github.PullRequest) projectContent {
+	return projectContent{
+		kind:           contentPullRequest,
+		id:             pr.ID,
+		url:            pr.URL,
+		author:         pr.Author.Login,
+		authorAssigned: pr.IsAuthorAssigned(),
+		itemID:         pr.ProjectItemID,
+	}
+}
+
+func issueContent(issue *github.Issue) projectContent {
+	return projectContent{
+		kind:           contentIssue,
+		id:             issue.ID,
+		url:            issue.URL,
+		author:         issue.Author.Login,
+		authorAssigned: issue.IsAuthorAssigned(),
+		itemID:         issue.ProjectItemID,
+	}
+}
+
+// addProjectItem assigns the author if requested, adds the content
+// to the project and sets the field values of the newly added project item.
+func addProjectItem(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	authors authorResolver,
+	project *github.Project,
+	content projectContent,
+	assignAuthor bool,
+	fields []fieldValue,
+) error {
+	if !content.authorAssigned && assignAuthor {
+		userID, err := authors.GetID(ctx, content.author)
+		if err != nil {
+			return fmt.Errorf("error looking up user %s: %w", content.author, err)
+		}
+
+		if cfg.verbose {
+			fmt.Fprintln(cfg.out(), "        Assigning author")
+		}
+
+		if userID != "" && !cfg.dryRun {
+			assign := client.AddAssigneeToPullRequest
+			if content.kind == contentIssue {
+				assign = client.AddAssigneeToIssue
+			}
+			if err := assign(ctx, content.id, userID); err != nil {
+				return fmt.Errorf("error adding assignee %s to the %s %s: %w", content.author, content.kind, content.url, err)
+			}
+		}
+	}
+
+	if cfg.verbose {
+		fmt.Fprintln(cfg.out(), "        Adding to project")
+	}
+	if !cfg.dryRun {
+		add := client.AddPullRequestToProject
+		if content.kind == contentIssue {
+			add = client.AddIssueToProject
+		}
+		var err error
+		if content.itemID, err = add(ctx, project.ID, content.id); err != nil {
+			return fmt.Errorf("error adding %s %s to the project: %w", content.kind, content.url, err)
+		}
+	}
+
+	return setProjectItemFields(ctx, client, cfg, project, content, fields)
+}
+
+// setProjectItemFields sets the field values of the content's project item.
+func setProjectItemFields(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	project *github.Project,
+	content projectContent,
+	fields []fieldValue,
+) error {
+	for _, f := range fields {
+		if cfg.verbose {
+			fmt.Fprintf(cfg.out(), "        Setting %s to %s\n", f.field.Name, f.name)
+		}
+		if !cfg.dryRun {
+			if err := client.UpdateProjectItemFieldValue(ctx, project.ID, content.itemID, f.field.ID, f.value); err != nil {
+				return fmt.Errorf("error setting %s of %s %s: %w", f.field.Name, content.kind, content.url, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeProjectItem deletes or archives the content's project item.
+func removeProjectItem(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	project *github.Project,
+	content projectContent,
+	archive bool,
+) error {
+	if cfg.dryRun {
+		return nil
+	}
+
+	if archive {
+		if err := client.ArchiveProjectItem(ctx, project.ID, content.itemID); err != nil {
+			return fmt.Errorf("error archiving %s %s in the project: %w", content.kind, content.url, err)
+		}
+		return nil
+	}
+
+	if err := client.DeleteProjectItem(ctx, project.ID, content.itemID); err != nil {
+		return fmt.Errorf("error deleting %s %s from the project: %w", content.kind, content.url, err)
+	}
+
+	return nil
+}
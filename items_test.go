@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestAddProjectItem(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	client := &fakeGithubClient{
+		AddAssigneeToIssueFunc: func(ctx context.Context, issueID, userID string) error {
+			calls = append(calls, "assign issue "+issueID)
+			return nil
+		},
+		AddAssigneeToPullRequestFunc: func(ctx context.Context, prID, userID string) error {
+			calls = append(calls, "assign PR "+prID)
+			return nil
+		},
+		AddIssueToProjectFunc: func(ctx context.Context, projectID, issueID string) (string, error) {
+			calls = append(calls, "add issue "+issueID)
+			return "item-" + issueID, nil
+		},
+		AddPullRequestToProjectFunc: func(ctx context.Context, projectID, prID string) (string, error) {
+			calls = append(calls, "add PR "+prID)
+			return "item-" + prID, nil
+		},
+		LookupUserFunc: func(ctx context.Context, login string) (*github.User, error) {
+			return &github.User{ID: "U1", Login: login}, nil
+		},
+		UpdateProjectItemFieldValueFunc: func(ctx context.Context, projectID, itemID, fieldID string, value github.ProjectFieldValue) error {
+			calls = append(calls, "set "+fieldID+" of "+itemID)
+			return nil
+		},
+	}
+
+	authors, err := NewAuthors(ctx, newMemberships(client, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := &github.Project{ID: "project"}
+	fields := []fieldValue{{field: github.ProjectField{ID: "status", Name: "Status"}, name: "Todo"}}
+
+	pr := &github.PullRequest{ID: "PR1"}
+	pr.Author.Login = "user"
+	issue := &github.Issue{ID: "I1"}
+	issue.Author.Login = "user"
+
+	for _, content := range []projectContent{pullRequestContent(pr), issueContent(issue)} {
+		if err := addProjectItem(ctx, client, config{}, authors, project, content, true, fields); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"assign PR PR1", "add PR PR1", "set status of item-PR1",
+		"assign issue I1", "add issue I1", "set status of item-I1",
+	}
+	if !slices.Equal(want, calls) {
+		t.Fatalf("Expected %v, got %v", want, calls)
+	}
+}
+
+func TestRemoveProjectItem(t *testing.T) {
+	ctx := context.Background()
+
+	var archived, deleted []string
+	client := &fakeGithubClient{
+		ArchiveProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			archived = append(archived, projectItemID)
+			return nil
+		},
+		DeleteProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			deleted = append(deleted, projectItemID)
+			return errors.New("boom")
+		},
+	}
+	project := &github.Project{ID: "project"}
+
+	issue := &github.Issue{URL: "https://github.com/org/repo/issues/1", ProjectItemID: "item1"}
+	if err := removeProjectItem(ctx, client, config{}, project, issueContent(issue), true); err != nil {
+		t.Fatal(err)
+	}
+
+	err := removeProjectItem(ctx, client, config{}, project, issueContent(issue), false)
+	if want, got := "error deleting issue https://github.com/org/repo/issues/1 from the project: boom", err.Error(); want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+
+	if len(archived) != 1 || len(deleted) != 1 {
+		t.Fatalf("Expected one archived and one deleted item, got %v and %v", archived, deleted)
+	}
+
+	// Nothing is changed in dry run mode.
+	if err := removeProjectItem(ctx, client, config{dryRun: true}, project, issueContent(issue), false); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 1, len(deleted); want != got {
+		t.Fatalf("Expected %d deleted items, got %d", want, got)
+	}
+}
@@ -11,8 +11,10 @@ import (
 	"maps"
 	"net/http"
 	"os"
+	"os/signal"
 	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/pmatseykanets/prsync/github"
@@ -23,7 +25,7 @@ import (
 const httpTimeout = 15 * time.Second
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	if err := run(ctx); err != nil {
@@ -33,16 +35,31 @@ func main() {
 }
 
 type githubClient interface {
+	AddAssigneeToIssue(ctx context.Context, issueID, userID string) error
 	AddAssigneeToPullRequest(ctx context.Context, prID, userID string) error
-	AddPullRequestToProject(ctx context.Context, projectID, prID string) error
-	DeletePullRequestFromProject(ctx context.Context, projectID, projectItemID string) error
-	GetProject(ctx context.Context, owner string, number int) (*github.Project, error)
-	GetProjectPullRequests(ctx context.Context, owner string, number int) iter.Seq2[*github.PullRequest, error]
+	AddIssueToProject(ctx context.Context, projectID, issueID string) (string, error)
+	AddPullRequestToProject(ctx context.Context, projectID, prID string) (string, error)
+	ArchiveProjectItem(ctx context.Context, projectID, projectItemID string) error
+	DeleteProjectItem(ctx context.Context, projectID, projectItemID string) error
+	GetChildTeams(ctx context.Context, org, team string) iter.Seq2[*github.Team, error]
+	GetOwnerRepositories(ctx context.Context, owner string) iter.Seq2[*github.Repository, error]
+	GetProject(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error)
+	GetProjectFields(ctx context.Context, projectID string) ([]github.ProjectField, error)
+	GetProjectIssues(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.Issue, error]
+	GetProjectPullRequests(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.PullRequest, error]
+	GetPullRequest(ctx context.Context, owner, name string, number int) (*github.PullRequest, error)
+	GetRepository(ctx context.Context, owner, name string) (*github.Repository, error)
+	GetRepositoryIssues(ctx context.Context, owner string, name string, states []github.IssueState) iter.Seq2[*github.Issue, error]
 	GetRepositoryPullRequests(ctx context.Context, owner string, name string, states []github.PullRequestState) iter.Seq2[*github.PullRequest, error]
-	GetTeamMembers(ctx context.Context, owner, name string) ([]github.User, error)
-	GetUserOrganizations(ctx context.Context, login string) ([]github.Organization, error)
+	GetTeam(ctx context.Context, org, team string) (*github.Team, error)
+	GetTeamMembers(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error]
+	GetTeamRepositories(ctx context.Context, org, team string) iter.Seq2[*github.Repository, error]
+	GetUserOrganizations(ctx context.Context, login string) iter.Seq2[*github.Organization, error]
 	LookupUser(ctx context.Context, login string) (*github.User, error)
 	IsOrganizationMember(ctx context.Context, login, org string) (bool, error)
+	RateLimit() github.RateLimitUsage
+	SearchRepositories(ctx context.Context, query string) iter.Seq2[*github.Repository, error]
+	UpdateProjectItemFieldValue(ctx context.Context, projectID, itemID, fieldID string, value github.ProjectFieldValue) error
 }
 
 type authorResolver interface {
@@ -51,15 +68,33 @@ type authorResolver interface {
 }
 
 func run(ctx context.Context) error {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			return serve(ctx, os.Args[2:])
+		case "explain":
+			return explain(ctx, os.Args[2:])
+		}
+	}
+
 	var (
 		configPath          string
+		tokenFile           string
 		dryRun, showVersion bool
 		verbose             bool
+		daemon              bool
+		interval, cacheTTL  time.Duration
+		output              string
 	)
 	flag.StringVar(&configPath, "config", "config.yaml", "Path to the config file")
+	flag.StringVar(&tokenFile, "token-file", "", "Path to a file with the GitHub token")
 	flag.BoolVar(&dryRun, "dry-run", false, "Dry run")
 	flag.BoolVar(&verbose, "verbose", false, "Verbose output")
 	flag.BoolVar(&showVersion, "version", showVersion, "Print version and exit")
+	flag.BoolVar(&daemon, "daemon", false, "Run continuously syncing every interval")
+	flag.DurationVar(&interval, "interval", 5*time.Minute, "Sync interval in daemon mode")
+	flag.DurationVar(&cacheTTL, "cache-ttl", time.Hour, "How long to cache team and organization memberships in daemon mode")
+	flag.StringVar(&output, "output", string(outputText), "Output format: text, json or ndjson")
 	flag.Parse()
 
 	if showVersion {
@@ -67,227 +102,695 @@ func run(ctx context.Context) error {
 		return nil
 	}
 
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return fmt.Errorf("GITHUB_TOKEN is required")
+	if daemon && interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+
+	format, err := parseOutputFormat(output)
+	if err != nil {
+		return err
+	}
+	report := newReporter(format, os.Stdout)
+	progress := io.Writer(os.Stdout)
+	if report.enabled() {
+		// Keep stdout for the records and print the progress to stderr.
+		progress = os.Stderr
+	}
+
+	cfg, client, err := setup(ctx, progress, configPath, tokenFile, dryRun, verbose)
+	if err != nil {
+		return err
 	}
+	cfg.report = report
+
+	// Team and organization memberships are shared between all jobs.
+	memberships := newMemberships(client, cfg.verbose)
+	memberships.progress = progress
+
+	if !daemon {
+		return syncAll(ctx, client, cfg, memberships)
+	}
+
+	fmt.Fprintf(progress, "  Interval: %s\n", interval)
+
+	memberships.ttl = cacheTTL
+	for ctx.Err() == nil {
+		if err := syncOnce(ctx, client, cfg, memberships); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(progress, "Sync failed: %s\n", err)
+		}
 
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	fmt.Fprintln(progress, "Stopped")
+
+	return nil
+}
+
+// setup reads the config and creates a GitHub client
+// after checking that the API endpoint is usable and the token has the access the config needs.
+func setup(ctx context.Context, progress io.Writer, configPath, tokenFile string, dryRun, verbose bool) (config, *github.Client, error) {
 	cfgRaw, err := os.ReadFile(configPath)
 	if err != nil {
-		return fmt.Errorf("error reading config %s: %w", configPath, err)
+		return config{}, nil, fmt.Errorf("error reading config %s: %w", configPath, err)
 	}
 
 	cfg, err := parseConfig(bytes.NewReader(cfgRaw))
 	if err != nil {
-		return fmt.Errorf("error parsing config: %w", err)
+		return config{}, nil, fmt.Errorf("error parsing config: %w", err)
 	}
 
 	cfg.path = configPath
+	cfg.progress = progress
+	cfg.tokenFile = tokenFile
 	cfg.dryRun = dryRun
 	cfg.verbose = verbose
 
-	fmt.Printf("Config file: %s\n", cfg.path)
-	fmt.Printf("  Dry run: %t\n", cfg.dryRun)
+	fmt.Fprintf(cfg.out(), "Config file: %s\n", cfg.path)
+	fmt.Fprintf(cfg.out(), "  Dry run: %t\n", cfg.dryRun)
 
-	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	))
+	tokenSource, err := newTokenSource(ctx, cfg)
+	if err != nil {
+		return config{}, nil, err
+	}
+
+	httpClient := oauth2.NewClient(ctx, tokenSource)
 	httpClient.Timeout = httpTimeout
 
-	if err := checkGitHubURL(ctx, cfg.githubURL, httpClient); err != nil {
-		return fmt.Errorf("error checking API endpoint: %w", err)
+	scopes, err := checkGitHubURL(ctx, cfg.githubURL, httpClient, cfg.githubApp != nil)
+	if err != nil {
+		return config{}, nil, fmt.Errorf("error checking API endpoint: %w", err)
 	}
 
 	client := github.NewClient(httpClient, cfg.githubURL)
+	client.OnRateLimit(func(wait time.Duration) {
+		fmt.Fprintf(cfg.out(), "Rate limited, retrying in %s\n", wait.Round(time.Second))
+	})
+	client.SetMaxAttempts(cfg.githubMaxAttempts)
+	client.OnRetry(func(attempt int, wait time.Duration, err error) {
+		fmt.Fprintf(cfg.out(), "Request failed (%s), retrying in %s (attempt %d of %d)\n", err, wait.Round(time.Millisecond), attempt+1, cfg.githubMaxAttempts)
+	})
+
+	if err := preflight(ctx, cfg.out(), client, cfg, scopes); err != nil {
+		return config{}, nil, err
+	}
+
+	return cfg, client, nil
+}
 
+// syncOnce runs a single daemon iteration making sure
+// that neither an error nor a panic stops the daemon.
+func syncOnce(ctx context.Context, client githubClient, cfg config, memberships *memberships) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	memberships.expire(time.Now())
+
+	return syncAll(ctx, client, cfg, memberships)
+}
+
+// syncAll runs all jobs and prints the summary.
+func syncAll(ctx context.Context, client githubClient, cfg config, memberships *memberships) error {
 	startedAt := time.Now()
+	rateLimit := client.RateLimit()
+
+	results := make([]jobResult, 0, len(cfg.jobs))
+	for _, job := range cfg.jobs {
+		if len(cfg.jobs) > 1 {
+			fmt.Fprintf(cfg.out(), "Job: %s\n", job.name)
+		}
+
+		result, err := syncJob(ctx, client, cfg, job, memberships)
+		if err != nil && len(cfg.jobs) > 1 {
+			fmt.Fprintf(cfg.out(), "Job %s failed: %s\n", job.name, err)
+		}
+		result.err = err
+		results = append(results, result)
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+
+	if len(results) > 1 {
+		fmt.Fprintln(cfg.out(), "Summary:")
+		for _, result := range results {
+			if result.err != nil {
+				fmt.Fprintf(cfg.out(), "  - %s: FAILED %s\n", result.name, result.err)
+				continue
+			}
+			fmt.Fprintf(cfg.out(), "  - %s: added %d, updated %d, deleted %d pull requests", result.name, result.added, result.updated, result.deleted)
+			if result.issues {
+				fmt.Fprintf(cfg.out(), "; added %d, deleted %d issues", result.issuesAdded, result.issuesDeleted)
+			}
+			fmt.Fprintln(cfg.out())
+		}
+	}
+
+	// Timing and rate limit usage are reported even if jobs failed.
+	fmt.Fprintf(cfg.out(), "Took %f sec\n", time.Since(startedAt).Seconds())
+
+	usage := client.RateLimit()
+	rateLimitUsed := usage.Used - rateLimit.Used
+	if !usage.ResetAt.IsZero() {
+		fmt.Fprintf(cfg.out(), "Rate limit: used %d points, %d remaining until %s\n", rateLimitUsed, usage.Remaining, usage.ResetAt.Local().Format(time.TimeOnly))
+	}
 
-	authors, err := NewAuthors(ctx, client, cfg)
+	cfg.report.summary(startedAt, results, rateLimitUsed)
+
+	switch {
+	case failed == 0:
+		return nil
+	case len(results) == 1:
+		return results[0].err
+	default:
+		return fmt.Errorf("%d of %d jobs failed", failed, len(results))
+	}
+}
+
+// jobResult holds the outcome of a single sync job.
+type jobResult struct {
+	name          string
+	added         int
+	updated       int
+	deleted       int
+	issues        bool
+	issuesAdded   int
+	issuesDeleted int
+	err           error
+}
+
+// syncJob syncs pull requests and issues to the job's project.
+func syncJob(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	memberships *memberships,
+) (jobResult, error) {
+	result := jobResult{name: job.name}
+
+	authors, err := NewAuthors(ctx, memberships, job.authors)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	project, err := client.GetProject(ctx, cfg.project.owner, cfg.project.number)
+	reviewers, err := NewReviewers(ctx, memberships, job.reviewers)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	projectPRs, err := getProjectPullRequests(ctx, client, cfg)
+	project, err := client.GetProject(ctx, job.project.owner, job.project.ownerType, job.project.number)
 	if err != nil {
-		return fmt.Errorf("error fetching project pull requests: %w", err)
+		return result, err
 	}
+	// Use the resolved owner type for the rest of the project queries.
+	job.project.ownerType = project.Owner.Type
 
-	fmt.Printf("Project: %d %s (%d pull requests)\n", project.Number, project.Title, len(projectPRs))
+	if job.repos, err = resolveRepos(ctx, client, cfg, job); err != nil {
+		return result, err
+	}
 
-	err = addNewPullRequests(ctx, client, cfg, authors, project, projectPRs)
+	projectPRs, err := getProjectPullRequests(ctx, client, cfg, job)
 	if err != nil {
-		return err
+		return result, fmt.Errorf("error fetching project pull requests: %w", err)
 	}
-	err = deleteCompletedPullRequests(ctx, client, cfg, authors, project, projectPRs)
+
+	fmt.Fprintf(cfg.out(), "Project: %d %s (%d pull requests)\n", project.Number, project.Title, len(projectPRs))
+
+	fields, err := resolveJobFields(ctx, client, job, project)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	fmt.Printf("Took %f sec\n", time.Since(startedAt).Seconds())
+	result.added, err = addNewPullRequests(ctx, client, cfg, job, authors, reviewers, project, projectPRs, fields.addPullRequests)
+	if err != nil {
+		return result, err
+	}
+	result.updated, err = updatePullRequests(ctx, client, cfg, job, authors, project, projectPRs, fields.updateRules)
+	if err != nil {
+		return result, err
+	}
+	result.deleted, err = deleteCompletedPullRequests(ctx, client, cfg, job, authors, project, projectPRs)
+	if err != nil {
+		return result, err
+	}
 
-	return nil
+	if !job.issues.enabled {
+		return result, nil
+	}
+	result.issues = true
+
+	projectIssues, err := getProjectIssues(ctx, client, cfg, job)
+	if err != nil {
+		return result, fmt.Errorf("error fetching project issues: %w", err)
+	}
+
+	fmt.Fprintf(cfg.out(), "Project: %d %s (%d issues)\n", project.Number, project.Title, len(projectIssues))
+
+	result.issuesAdded, err = addNewIssues(ctx, client, cfg, job, authors, project, projectIssues, fields.addIssues)
+	if err != nil {
+		return result, err
+	}
+	result.issuesDeleted, err = deleteCompletedIssues(ctx, client, cfg, job, authors, project, projectIssues)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// jobFields holds the job's field values resolved against the project fields.
+type jobFields struct {
+	addPullRequests []fieldValue
+	updateRules     []updateRule
+	addIssues       []fieldValue
+}
+
+// resolveJobFields resolves the configured field values of the job.
+// Project fields are only fetched if the job sets any field values.
+func resolveJobFields(ctx context.Context, client githubClient, job configJob, project *github.Project) (jobFields, error) {
+	var resolved jobFields
+
+	if len(job.pullRequests.add.fields) == 0 && len(job.pullRequests.update.rules) == 0 && len(job.issues.add.fields) == 0 {
+		return resolved, nil
+	}
+
+	fields, err := client.GetProjectFields(ctx, project.ID)
+	if err != nil {
+		return resolved, fmt.Errorf("error fetching project fields: %w", err)
+	}
+
+	now := time.Now()
+	resolved.addPullRequests, err = resolveFieldValues(fields, job.pullRequests.add.fields, now)
+	if err != nil {
+		return resolved, fmt.Errorf("error resolving pullRequests.add.fields: %w", err)
+	}
+
+	resolved.addIssues, err = resolveFieldValues(fields, job.issues.add.fields, now)
+	if err != nil {
+		return resolved, fmt.Errorf("error resolving issues.add.fields: %w", err)
+	}
+
+	for i, rule := range job.pullRequests.update.rules {
+		ruleFields, err := resolveFieldValues(fields, rule.set, now)
+		if err != nil {
+			return resolved, fmt.Errorf("error resolving pullRequests.update rule %d: %w", i+1, err)
+		}
+		resolved.updateRules = append(resolved.updateRules, updateRule{when: rule.when, fields: ruleFields})
+	}
+
+	return resolved, nil
 }
 
 // addNewPullRequests adds new pull requests to the project
-// based on the author, state, and draft status of the pull request.
+// based on the author, state, and draft status of the pull request
+// and sets the field values of the newly added project items.
 func addNewPullRequests(
 	ctx context.Context,
 	client githubClient,
 	cfg config,
+	job configJob,
 	authors authorResolver,
+	reviewers reviewerResolver,
 	project *github.Project,
 	projectPRs map[prKey]*github.PullRequest,
-) error {
+	fields []fieldValue,
+) (int, error) {
 	var addCount int
-	fmt.Println("Checking for pull requests to add:")
-	for _, repository := range cfg.repos {
-		fmt.Printf("  - %s/%s\n", repository.owner, repository.name)
-		for pr, err := range getAuthorsPullRequests(ctx, client, cfg, authors, repository.owner, repository.name) {
+	fmt.Fprintln(cfg.out(), "Checking for pull requests to add:")
+	for _, repository := range job.repos {
+		fmt.Fprintf(cfg.out(), "  - %s/%s\n", repository.owner, repository.name)
+		for pr, err := range getAuthorsPullRequests(ctx, client, cfg, job, authors, reviewers, repository.owner, repository.name) {
 			if err != nil {
-				return fmt.Errorf("error fetching authors' pull requests: %w", err)
+				return addCount, fmt.Errorf("error fetching authors' pull requests: %w", err)
 			}
 
 			key := prKey{owner: pr.Repository.Owner.Login, repo: pr.Repository.Name, number: pr.Number}
 			if _, ok := projectPRs[key]; ok {
 				if cfg.verbose {
-					fmt.Printf("    - %s %s %s %s %s EXISTS\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+					fmt.Fprintf(cfg.out(), "    - %s %s %s %s %s EXISTS\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 				}
+				cfg.report.pullRequest(job, "add", pr, decisionExists, "already in the project", "", nil)
 				continue
 			}
 
 			if cfg.verbose {
-				fmt.Printf("    - %s %s %s %s %s NEW \n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s %s NEW \n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 			} else {
-				fmt.Printf("    - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
-
-			}
-
-			if !pr.IsAuthorAssigned() && cfg.pullRequests.add.assignAuthor {
-				userID, err := authors.GetID(ctx, pr.Author.Login)
-				if err != nil {
-					return fmt.Errorf("error looking up user %s: %w", pr.Author.Login, err)
-				}
-
-				if cfg.verbose {
-					fmt.Println("        Assigning author")
-				}
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 
-				if userID != "" && !cfg.dryRun {
-					if err := client.AddAssigneeToPullRequest(ctx, pr.ID, userID); err != nil {
-						return fmt.Errorf("error adding assignee %s to the PR %s: %w", pr.Author.Login, pr.URL, err)
-					}
-				}
 			}
 
-			// Sanity check.
-			for _, prj := range pr.Projects.Nodes {
-				if prj.Owner.Login == cfg.project.owner && prj.Number == cfg.project.number {
-					continue // PR is already linked to the project.
-				}
-			}
-
-			if cfg.verbose {
-				fmt.Println("        Adding to project")
-			}
-			if !cfg.dryRun {
-				if err := client.AddPullRequestToProject(ctx, project.ID, pr.ID); err != nil {
-					return fmt.Errorf("error adding PR %s to the project: %w", pr.URL, err)
-				}
+			if err := addPullRequest(ctx, client, cfg, job, authors, project, pr, fields); err != nil {
+				cfg.report.pullRequest(job, "add", pr, decisionAdd, "", "", err)
+				return addCount, err
 			}
+			cfg.report.pullRequest(job, "add", pr, decisionAdd, "", actionResult(cfg), nil)
+			addCount++
 		}
 	}
 
 	if addCount > 0 {
-		fmt.Printf("Added %d pull requests\n", addCount)
+		fmt.Fprintf(cfg.out(), "Added %d pull requests\n", addCount)
 	} else {
-		fmt.Println("No pull requests to add")
+		fmt.Fprintln(cfg.out(), "No pull requests to add")
 	}
 
-	return nil
+	return addCount, nil
 }
 
-// deleteCompletedPullRequests deletes pull requests from the project
-// that match the state or draft status.
-// It takes authors into consideration if cfg.pullRequests.delete.allAuthors is false.
-func deleteCompletedPullRequests(
+// updatePullRequests sets project field values of the pull requests
+// already in the project according to the first matching update rule.
+// It takes authors into consideration if job.pullRequests.update.allAuthors is false.
+func updatePullRequests(
 	ctx context.Context,
 	client githubClient,
 	cfg config,
+	job configJob,
 	authors authorResolver,
 	project *github.Project,
 	projectPRs map[prKey]*github.PullRequest,
-) error {
-	if len(cfg.pullRequests.delete.states) == 0 && !cfg.pullRequests.delete.drafts {
-		return nil // Nothing else to do.
+	rules []updateRule,
+) (int, error) {
+	if len(rules) == 0 {
+		return 0, nil // Nothing else to do.
 	}
 
-	fmt.Println("Checking for pull requests to delete:")
+	fmt.Fprintln(cfg.out(), "Checking for pull requests to update:")
 
-	var deleteCount int
+	var updateCount int
 	for _, pr := range projectPRs {
-		if !cfg.pullRequests.delete.allAuthors {
-			ourAuthor, err := authors.Resolve(ctx, pr.Author.Login)
-			if err != nil {
-				return fmt.Errorf("error checking if %s is our author: %w", pr.Author.Login, err)
-			}
+		changes, ok, err := pullRequestFieldChanges(ctx, job, authors, rules, pr)
+		if err != nil {
+			return updateCount, err
+		}
+		if !ok {
+			continue
+		}
 
+		if len(changes) == 0 {
 			if cfg.verbose {
-				fmt.Printf("  - %s %s %s %s %s SKIP\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+				fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s UNCHANGED\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 			}
+			cfg.report.pullRequest(job, "update", pr, decisionKeep, "unchanged", "", nil)
+			continue
+		}
 
-			if !ourAuthor {
-				continue
-			}
+		updateCount++
+
+		if cfg.verbose {
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s UPDATE\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+		} else {
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 		}
 
-		delete := pr.IsDraft && cfg.pullRequests.delete.drafts
-		if !delete {
-			for _, state := range cfg.pullRequests.delete.states {
-				if pr.State == state {
-					delete = true
-					break
-				}
-			}
+		if err := setProjectItemFields(ctx, client, cfg, project, pullRequestContent(pr), changes); err != nil {
+			cfg.report.pullRequest(job, "update", pr, decisionUpdate, "", "", err)
+			return updateCount, err
 		}
+		cfg.report.pullRequest(job, "update", pr, decisionUpdate, "", actionResult(cfg), nil)
+	}
 
-		if cfg.verbose {
-			fmt.Printf("  - %s %s %s %s %s", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+	if updateCount > 0 {
+		fmt.Fprintf(cfg.out(), "Updated %d pull requests\n", updateCount)
+	} else {
+		fmt.Fprintln(cfg.out(), "No pull requests to update")
+	}
+
+	return updateCount, nil
+}
+
+// deleteCompletedPullRequests deletes or archives pull requests from the project
+// that match the state, draft status, review decision, checks, staleness or delete labels and branches.
+// It takes authors into consideration if job.pullRequests.delete.allAuthors is false.
+func deleteCompletedPullRequests(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	project *github.Project,
+	projectPRs map[prKey]*github.PullRequest,
+) (int, error) {
+	if !deletesPullRequests(job) {
+		return 0, nil // Nothing else to do.
+	}
+
+	archive := job.pullRequests.delete.mode == deleteModeArchive
+	action, actionPast := decisionDelete, "Deleted"
+	if archive {
+		action, actionPast = decisionArchive, "Archived"
+	}
+
+	fmt.Fprintf(cfg.out(), "Checking for pull requests to %s:\n", action)
+
+	now := time.Now()
+	var deleteCount int
+	for _, pr := range projectPRs {
+		decision, reason, err := pullRequestDeleteDecision(ctx, job, authors, pr, now)
+		if err != nil {
+			return deleteCount, err
 		}
 
-		if !delete {
+		switch decision {
+		case decisionKeep:
 			if cfg.verbose {
-				fmt.Println(" KEEP")
+				status := "KEEP"
+				if reason == reasonAlreadyArchived {
+					status = "ARCHIVED"
+				}
+				fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft), status)
 			}
+			cfg.report.pullRequest(job, "delete", pr, decision, reason, "", nil)
+			continue
+		case decisionSkip:
+			if cfg.verbose {
+				fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s SKIP\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+			}
+			cfg.report.pullRequest(job, "delete", pr, decision, reason, "", nil)
 			continue
 		}
 
 		deleteCount++
 
 		if cfg.verbose {
-			fmt.Println(" DELETE")
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft), strings.ToUpper(action))
 		} else {
-			fmt.Printf("  - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+			fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 		}
 
-		if !cfg.dryRun {
-			if err := client.DeletePullRequestFromProject(ctx, project.ID, pr.ProjectItemID); err != nil {
-				return fmt.Errorf("error deleting PR %s from the project: %w", pr.URL, err)
-			}
+		if err := removeProjectItem(ctx, client, cfg, project, pullRequestContent(pr), archive); err != nil {
+			cfg.report.pullRequest(job, "delete", pr, action, reason, "", err)
+			return deleteCount, err
 		}
+		cfg.report.pullRequest(job, "delete", pr, action, reason, actionResult(cfg), nil)
 	}
 
 	if deleteCount > 0 {
-		fmt.Printf("Deleted %d pull requests\n", deleteCount)
+		fmt.Fprintf(cfg.out(), "%s %d pull requests\n", actionPast, deleteCount)
 	} else {
-		fmt.Println("No pull requests to delete")
+		fmt.Fprintf(cfg.out(), "No pull requests to %s\n", action)
 	}
 
-	return nil
+	return deleteCount, nil
+}
+
+// pullRequestFieldChanges returns the field values of the first update rule matching the pull request
+// that differ from the values of its project item.
+// It reports false if no update rule applies to the pull request.
+// It takes authors into consideration if job.pullRequests.update.allAuthors is false.
+func pullRequestFieldChanges(
+	ctx context.Context,
+	job configJob,
+	authors authorResolver,
+	rules []updateRule,
+	pr *github.PullRequest,
+) ([]fieldValue, bool, error) {
+	// Archived items are left as is.
+	if pr.IsArchived() {
+		return nil, false, nil
+	}
+
+	if !job.pullRequests.update.allAuthors {
+		ourAuthor, err := authors.Resolve(ctx, pr.Author.Login)
+		if err != nil {
+			return nil, false, fmt.Errorf("error checking if %s is our author: %w", pr.Author.Login, err)
+		}
+		if !ourAuthor {
+			return nil, false, nil
+		}
+	}
+
+	rule, ok := matchUpdateRule(rules, pr)
+	if !ok {
+		return nil, false, nil
+	}
+
+	return changedFieldValues(pr, rule.fields), true, nil
+}
+
+// pullRequestDeleteDecision returns whether the pull request should be deleted (archived)
+// from the project or kept in it (skipped) and the reason of the decision.
+// It takes authors into consideration if job.pullRequests.delete.allAuthors is false.
+func pullRequestDeleteDecision(
+	ctx context.Context,
+	job configJob,
+	authors authorResolver,
+	pr *github.PullRequest,
+	now time.Time,
+) (string, string, error) {
+	archive := job.pullRequests.delete.mode == deleteModeArchive
+
+	// Already archived items don't need to be archived again.
+	if archive && pr.IsArchived() {
+		return decisionKeep, reasonAlreadyArchived, nil
+	}
+
+	if !job.pullRequests.delete.allAuthors {
+		ourAuthor, err := authors.Resolve(ctx, pr.Author.Login)
+		if err != nil {
+			return "", "", fmt.Errorf("error checking if %s is our author: %w", pr.Author.Login, err)
+		}
+		if !ourAuthor {
+			return decisionSkip, reasonAuthorNotIncluded, nil
+		}
+	}
+
+	reason := deleteReason(job, pr, now)
+	switch {
+	case reason == "":
+		return decisionKeep, "", nil
+	case archive:
+		return decisionArchive, reason, nil
+	default:
+		return decisionDelete, reason, nil
+	}
+}
+
+// addPullRequest assigns the author if configured, adds the pull request
+// to the project and sets the field values of the newly added project item.
+func addPullRequest(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	project *github.Project,
+	pr *github.PullRequest,
+	fields []fieldValue,
+) error {
+	// Sanity check.
+	for _, prj := range pr.Projects.Nodes {
+		if prj.Owner.Login == job.project.owner && prj.Number == job.project.number {
+			continue // PR is already linked to the project.
+		}
+	}
+
+	return addProjectItem(ctx, client, cfg, authors, project, pullRequestContent(pr), job.pullRequests.add.assignAuthor, fields)
+}
+
+// changedFieldValues returns only the values that differ from the current ones.
+func changedFieldValues(pr *github.PullRequest, fields []fieldValue) []fieldValue {
+	var changes []fieldValue
+	for _, f := range fields {
+		if pr.ProjectItem != nil {
+			if current, ok := pr.ProjectItem.FieldValue(f.field.ID); ok && current.Equal(f.value) {
+				continue
+			}
+		}
+		changes = append(changes, f)
+	}
+
+	return changes
+}
+
+// isAddCandidate reports whether the pull request should be added to the project
+// according to its state, draft status, labels, branches, age, review decision, checks, author and reviewers
+// unless it would be deleted right away.
+func isAddCandidate(
+	ctx context.Context,
+	job configJob,
+	authors authorResolver,
+	reviewers reviewerResolver,
+	pr *github.PullRequest,
+	now time.Time,
+) (bool, error) {
+	reason, err := addSkipReason(ctx, job, authors, reviewers, pr, now)
+	return err == nil && reason == "", err
+}
+
+// addSkipReason returns the first add criterion the pull request fails
+// or an empty string if the pull request should be added to the project.
+func addSkipReason(
+	ctx context.Context,
+	job configJob,
+	authors authorResolver,
+	reviewers reviewerResolver,
+	pr *github.PullRequest,
+	now time.Time,
+) (string, error) {
+	for _, rule := range addRules(job, now) {
+		if !rule.matches(pr) {
+			return "failed: " + rule.name, nil
+		}
+	}
+
+	// Pull requests that the delete phase would remove right away aren't added
+	// so that they don't get added and deleted again on every other sync.
+	if reason := deleteReason(job, pr, now); reason != "" {
+		return "would be deleted: " + reason, nil
+	}
+
+	includedAuthor, err := authors.Resolve(ctx, pr.Author.Login)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating author filter for %s: %w", pr.Author.Login, err)
+	}
+
+	if !includedAuthor {
+		return reasonAuthorNotIncluded, nil
+	}
+
+	includedReviewer, err := reviewers.Resolve(ctx, pr)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating reviewer filter for %s: %w", pr.URL, err)
+	}
+
+	if !includedReviewer {
+		return reasonNoIncludedReviewer, nil
+	}
+
+	return "", nil
+}
+
+// isDeleteCandidate reports whether the pull request should be deleted from the project
+// according to its state, draft status, review decision, checks and staleness
+// or because its labels or branches no longer match.
+func isDeleteCandidate(job configJob, pr *github.PullRequest, now time.Time) bool {
+	return deleteReason(job, pr, now) != ""
+}
+
+// deleteReason returns the first delete criterion the pull request matches
+// or an empty string if the pull request should be kept in the project.
+func deleteReason(job configJob, pr *github.PullRequest, now time.Time) string {
+	for _, rule := range deleteRules(job, now) {
+		if rule.matches(pr) {
+			return rule.name
+		}
+	}
+
+	return ""
 }
 
 // draftState returns the string representation of the draft state of the pull request.
@@ -299,24 +802,24 @@ func draftState(draft bool) string {
 }
 
 // checkGitHubURL checks if the provided URL is a valid GitHub API endpoint
-// by exercising the GraphQL and REST endpoints.
-func checkGitHubURL(ctx context.Context, url string, httpClient *http.Client) error {
-	doRequest := func(method, url string, body io.Reader) error {
+// by exercising the GraphQL and REST endpoints, and returns the token scopes.
+func checkGitHubURL(ctx context.Context, url string, httpClient *http.Client, installation bool) ([]string, error) {
+	doRequest := func(method, url string, body io.Reader) (http.Header, error) {
 		req, err := http.NewRequestWithContext(ctx, method, url, body)
 		if err != nil {
-			return fmt.Errorf("error creating request: %w", err)
+			return nil, fmt.Errorf("error creating request: %w", err)
 		}
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Accept", "application/json")
 
 		resp, err := httpClient.Do(req)
 		if err != nil {
-			return fmt.Errorf("error making request: %w", err)
+			return nil, fmt.Errorf("error making request: %w", err)
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode == http.StatusOK {
-			return nil
+			return resp.Header, nil
 		}
 
 		message := http.StatusText(resp.StatusCode)
@@ -327,7 +830,7 @@ func checkGitHubURL(ctx context.Context, url string, httpClient *http.Client) er
 			message = githubError.Message
 		}
 
-		return fmt.Errorf("%d %s", resp.StatusCode, message)
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, message)
 	}
 
 	// Check GraphQL endpoint.
@@ -338,19 +841,45 @@ func checkGitHubURL(ctx context.Context, url string, httpClient *http.Client) er
 		Query: github.NewViewerQuery(),
 	})
 	if err != nil {
-		return fmt.Errorf("error encoding request body: %w", err)
+		return nil, fmt.Errorf("error encoding request body: %w", err)
 	}
 
-	if err := doRequest(http.MethodPost, url+"/graphql", &body); err != nil {
-		return fmt.Errorf("error checking GraphQL endpoint: %w", err)
+	if _, err := doRequest(http.MethodPost, url+"/graphql", &body); err != nil {
+		return nil, fmt.Errorf("error checking GraphQL endpoint: %w", err)
 	}
 
 	// Check REST endpoint.
-	if err := doRequest(http.MethodGet, url+"/user", nil); err != nil {
-		return fmt.Errorf("error checking REST endpoint: %w", err)
+	// Installation tokens don't have access to the authenticated user.
+	restPath := "/user"
+	if installation {
+		restPath = "/installation/repositories?per_page=1"
+	}
+	header, err := doRequest(http.MethodGet, url+restPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error checking REST endpoint: %w", err)
 	}
 
-	return nil
+	return parseScopes(header), nil
+}
+
+// parseScopes returns the OAuth scopes of a classic personal access token.
+// Fine-grained personal access tokens and installation tokens have no scopes and nil is returned.
+func parseScopes(header http.Header) []string {
+	values := header.Values("X-OAuth-Scopes")
+	if values == nil {
+		return nil
+	}
+
+	scopes := []string{}
+	for _, value := range values {
+		for _, scope := range strings.Split(value, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return scopes
 }
 
 type prKey struct {
@@ -359,19 +888,31 @@ type prKey struct {
 	number int
 }
 
+// comparePRKeys orders keys by the repository owner, name and number.
+func comparePRKeys(i, j prKey) int {
+	if i.owner != j.owner {
+		return strings.Compare(i.owner, j.owner)
+	}
+	if i.repo != j.repo {
+		return strings.Compare(i.repo, j.repo)
+	}
+	return i.number - j.number
+}
+
 // getProjectPullRequests returns the project information and all of its pull requests.
 func getProjectPullRequests(
 	ctx context.Context,
 	client githubClient,
 	cfg config,
+	job configJob,
 ) (map[prKey]*github.PullRequest, error) {
 	projectPRs := make(map[prKey]*github.PullRequest)
 
 	if cfg.verbose {
-		fmt.Println("Fetching project info and pull requests")
+		fmt.Fprintln(cfg.out(), "Fetching project info and pull requests")
 	}
 
-	for pr, err := range client.GetProjectPullRequests(ctx, cfg.project.owner, cfg.project.number) {
+	for pr, err := range client.GetProjectPullRequests(ctx, job.project.owner, job.project.ownerType, job.project.number) {
 		if err != nil {
 			return nil, fmt.Errorf("error fetching project pull requests: %w", err)
 		}
@@ -382,26 +923,22 @@ func getProjectPullRequests(
 
 	if cfg.verbose {
 		keys := slices.Collect(maps.Keys(projectPRs))
-		slices.SortFunc(keys, func(i, j prKey) int {
-			if i.owner != j.owner {
-				return strings.Compare(i.owner, j.owner)
-			}
-			if i.repo != j.repo {
-				return strings.Compare(i.repo, j.repo)
-			}
-			return i.number - j.number
-		})
+		slices.SortFunc(keys, comparePRKeys)
 
 		var repo string
 		for _, key := range keys {
 			currentRepo := key.owner + "/" + key.repo
 			if repo != currentRepo {
 				repo = currentRepo
-				fmt.Printf("  - %s\n", repo)
+				fmt.Fprintf(cfg.out(), "  - %s\n", repo)
 			}
 
 			pr := projectPRs[key]
-			fmt.Printf("    - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+			if pr.IsArchived() {
+				fmt.Fprintf(cfg.out(), "    - %s %s %s %s %s ARCHIVED\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
+				continue
+			}
+			fmt.Fprintf(cfg.out(), "    - %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft))
 		}
 	}
 
@@ -415,34 +952,28 @@ func getAuthorsPullRequests(
 	ctx context.Context,
 	client githubClient,
 	cfg config,
+	job configJob,
 	authors authorResolver,
+	reviewers reviewerResolver,
 	owner string,
 	repo string,
 ) iter.Seq2[*github.PullRequest, error] {
 	return func(yield func(*github.PullRequest, error) bool) {
-		for pr, err := range client.GetRepositoryPullRequests(ctx, owner, repo, cfg.pullRequests.add.states) {
+		now := time.Now()
+		for pr, err := range client.GetRepositoryPullRequests(ctx, owner, repo, job.pullRequests.add.states) {
 			if err != nil {
 				yield(nil, fmt.Errorf("error fetching repository pull requests: %w", err))
 				return
 			}
 
-			// Skip draft PRs.
-			if pr.IsDraft && !cfg.pullRequests.add.drafts {
-				continue
-			}
-
-			// Skip PRs from non-users (e.g. bots).
-			if pr.Author.Type != github.AuthorTypeUser {
-				continue
-			}
-
-			includedAuthor, err := authors.Resolve(ctx, pr.Author.Login)
+			reason, err := addSkipReason(ctx, job, authors, reviewers, pr, now)
 			if err != nil {
-				yield(nil, fmt.Errorf("error evaluating author filter for %s: %w", pr.Author.Login, err))
+				yield(nil, err)
 				return
 			}
 
-			if !includedAuthor {
+			if reason != "" {
+				cfg.report.pullRequest(job, "add", pr, decisionSkip, reason, "", nil)
 				continue
 			}
 
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestUpdatePullRequests(t *testing.T) {
+	ctx := context.Background()
+	done, inProgress := "done", "in-progress"
+	draft := true
+
+	rules := []updateRule{
+		{
+			when:   configPullRequestCondition{draft: &draft},
+			fields: []fieldValue{{field: github.ProjectField{ID: "status"}, value: github.ProjectFieldValue{SingleSelectOptionID: &inProgress}}},
+		},
+		{
+			when:   configPullRequestCondition{states: []github.PullRequestState{github.PullRequestStateMerged}},
+			fields: []fieldValue{{field: github.ProjectField{ID: "status"}, value: github.ProjectFieldValue{SingleSelectOptionID: &done}}},
+		},
+	}
+
+	newPR := func(number int, state github.PullRequestState, isDraft bool, optionID string) *github.PullRequest {
+		item := &github.ProjectItem{ID: "item" + strconv.Itoa(number)}
+		if optionID != "" {
+			v := github.ProjectItemFieldValue{OptionID: optionID}
+			v.Field.ID = "status"
+			item.FieldValues.Nodes = append(item.FieldValues.Nodes, v)
+		}
+		return &github.PullRequest{Number: number, State: state, IsDraft: isDraft, ProjectItemID: item.ID, ProjectItem: item}
+	}
+	projectPRs := map[prKey]*github.PullRequest{
+		{"org", "repo", 1}: newPR(1, github.PullRequestStateOpen, true, ""),
+		{"org", "repo", 2}: newPR(2, github.PullRequestStateMerged, false, "in-progress"),
+		{"org", "repo", 3}: newPR(3, github.PullRequestStateMerged, false, "done"),
+		{"org", "repo", 4}: newPR(4, github.PullRequestStateOpen, false, ""),
+	}
+
+	updated := make(map[string]string)
+	client := &fakeGithubClient{
+		UpdateProjectItemFieldValueFunc: func(ctx context.Context, projectID, itemID, fieldID string, value github.ProjectFieldValue) error {
+			updated[itemID] = *value.SingleSelectOptionID
+			return nil
+		},
+	}
+
+	job := configJob{}
+	job.pullRequests.update.allAuthors = true
+
+	count, err := updatePullRequests(ctx, client, config{}, job, nil, &github.Project{ID: "project"}, projectPRs, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 2, count; want != got {
+		t.Fatalf("Expected %d updated, got %d", want, got)
+	}
+	if want, got := "in-progress", updated["item1"]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := "done", updated["item2"]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if _, ok := updated["item3"]; ok {
+		t.Fatal("Expected item3 to be unchanged")
+	}
+	if _, ok := updated["item4"]; ok {
+		t.Fatal("Expected item4 to be unchanged")
+	}
+}
+
+func TestDeleteCompletedPullRequestsArchive(t *testing.T) {
+	ctx := context.Background()
+
+	projectPRs := map[prKey]*github.PullRequest{
+		{"org", "repo", 1}: {Number: 1, State: github.PullRequestStateMerged, ProjectItemID: "item1", ProjectItem: &github.ProjectItem{ID: "item1"}},
+		{"org", "repo", 2}: {Number: 2, State: github.PullRequestStateMerged, ProjectItemID: "item2", ProjectItem: &github.ProjectItem{ID: "item2", IsArchived: true}},
+		{"org", "repo", 3}: {Number: 3, State: github.PullRequestStateOpen, ProjectItemID: "item3", ProjectItem: &github.ProjectItem{ID: "item3"}},
+	}
+
+	var archived []string
+	client := &fakeGithubClient{
+		ArchiveProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			archived = append(archived, projectItemID)
+			return nil
+		},
+		DeleteProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			t.Errorf("Unexpected call to DeleteProjectItem for %s", projectItemID)
+			return nil
+		},
+	}
+
+	job := configJob{}
+	job.pullRequests.delete.states = []github.PullRequestState{github.PullRequestStateMerged}
+	job.pullRequests.delete.allAuthors = true
+	job.pullRequests.delete.mode = deleteModeArchive
+
+	count, err := deleteCompletedPullRequests(ctx, client, config{}, job, nil, &github.Project{ID: "project"}, projectPRs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := 1, count; want != got {
+		t.Fatalf("Expected %d archived, got %d", want, got)
+	}
+	if want, got := []string{"item1"}, archived; !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestPullRequestDeleteDecision(t *testing.T) {
+	ctx := context.Background()
+
+	newPR := func(author string, state github.PullRequestState, archived bool) *github.PullRequest {
+		pr := &github.PullRequest{State: state, ProjectItem: &github.ProjectItem{IsArchived: archived}}
+		pr.Author.Login = author
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.delete.states = []github.PullRequestState{github.PullRequestStateMerged}
+	job.pullRequests.delete.mode = deleteModeArchive
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{exclude: configAuthorRules{users: []string{"outsider"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr       *github.PullRequest
+		decision string
+		reason   string
+	}{
+		{newPR("user", github.PullRequestStateMerged, false), decisionArchive, "state is one of [MERGED]"},
+		{newPR("user", github.PullRequestStateMerged, true), decisionKeep, reasonAlreadyArchived},
+		{newPR("user", github.PullRequestStateOpen, false), decisionKeep, ""},
+		{newPR("outsider", github.PullRequestStateMerged, false), decisionSkip, reasonAuthorNotIncluded},
+	}
+
+	for _, tt := range tests {
+		decision, reason, err := pullRequestDeleteDecision(ctx, job, authors, tt.pr, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.decision, decision; want != got {
+			t.Fatalf("Expected %s for %s, got %s", want, tt.pr.Author.Login, got)
+		}
+		if want, got := tt.reason, reason; want != got {
+			t.Fatalf("Expected reason %q for %s, got %q", want, tt.pr.Author.Login, got)
+		}
+	}
+}
+
+func TestSyncJobKeepsStalePullRequestsDeleted(t *testing.T) {
+	ctx := context.Background()
+
+	pr := github.PullRequest{ID: "PR", Number: 1, State: github.PullRequestStateOpen, UpdatedAt: time.Now().Add(-30 * 24 * time.Hour)}
+	pr.Author.Type, pr.Author.Login = github.AuthorTypeUser, "user"
+	pr.Repository.Owner.Login, pr.Repository.Name = "org", "repo"
+
+	// The project starts with the stale pull request in it.
+	items := map[string]bool{"item": true}
+	var calls []string
+	client := &fakeGithubClient{
+		AddPullRequestToProjectFunc: func(ctx context.Context, projectID, prID string) (string, error) {
+			calls = append(calls, "add "+prID)
+			items["item"] = true
+			return "item", nil
+		},
+		DeleteProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			calls = append(calls, "delete "+projectItemID)
+			delete(items, projectItemID)
+			return nil
+		},
+		GetProjectFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
+			return &github.Project{ID: "project"}, nil
+		},
+		GetProjectPullRequestsFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) iter.Seq2[*github.PullRequest, error] {
+			if !items["item"] {
+				return seqOf[github.PullRequest]()
+			}
+			item := pr
+			item.ProjectItemID, item.ProjectItem = "item", &github.ProjectItem{ID: "item"}
+			return seqOf(item)
+		},
+		GetRepositoryPullRequestsFunc: func(ctx context.Context, owner, name string, states []github.PullRequestState) iter.Seq2[*github.PullRequest, error] {
+			return seqOf(pr)
+		},
+	}
+
+	job := configJob{name: "job", repoSources: []configRepoSource{{kind: repoSourceName, owner: "org", name: "repo"}}}
+	job.pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
+	job.pullRequests.delete.staleAfter = 7 * 24 * time.Hour
+
+	cfg := config{progress: io.Discard}
+	for range 2 {
+		if _, err := syncJob(ctx, client, cfg, job, newMemberships(client, false)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want, got := []string{"delete item"}, calls; !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSyncAllReportsRateLimitOnFailure(t *testing.T) {
+	ctx := context.Background()
+
+	var used int
+	client := &fakeGithubClient{
+		GetProjectFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
+			used += 5
+			return nil, errors.New("boom")
+		},
+		RateLimitFunc: func() github.RateLimitUsage {
+			return github.RateLimitUsage{Used: used, Remaining: 100, ResetAt: time.Now().Add(time.Hour)}
+		},
+	}
+
+	var progress bytes.Buffer
+	cfg := config{jobs: []configJob{{name: "job"}}, progress: &progress}
+	err := syncAll(ctx, client, cfg, newMemberships(client, false))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Expected the job error, got %v", err)
+	}
+
+	for _, line := range []string{"Took ", "Rate limit: used 5 points"} {
+		if !strings.Contains(progress.String(), line) {
+			t.Fatalf("Expected %q in\n%s", line, progress.String())
+		}
+	}
+}
+
+func TestPullRequestLabelFilters(t *testing.T) {
+	ctx := context.Background()
+
+	newPR := func(labels ...string) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		for _, label := range labels {
+			pr.Labels.Nodes = append(pr.Labels.Nodes, github.Label{Name: label})
+		}
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.labels = configPatterns{include: []string{"needs-*"}, exclude: []string{"dependencies"}}
+	job.pullRequests.delete.labels = configPatterns{exclude: []string{"dependencies", "wontfix"}}
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr     *github.PullRequest
+		add    bool
+		delete bool
+	}{
+		{newPR(), false, false},
+		{newPR("bug"), false, false},
+		{newPR("needs-design-review"), true, false},
+		{newPR("needs-design-review", "dependencies"), false, true},
+		{newPR("wontfix"), false, true},
+	}
+
+	for _, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %v, got %t", want, tt.pr.LabelNames(), got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, time.Now()); want != got {
+			t.Fatalf("Expected delete %t for %v, got %t", want, tt.pr.LabelNames(), got)
+		}
+	}
+}
+
+func TestPullRequestBranchFilters(t *testing.T) {
+	ctx := context.Background()
+
+	newPR := func(base, head string) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen, BaseRefName: base, HeadRefName: head}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.baseBranches = configPatterns{include: []string{"main", "release/*"}}
+	job.pullRequests.add.headBranches = configPatterns{exclude: []string{"dependabot/*"}}
+	job.pullRequests.delete.baseBranches = configPatterns{include: []string{"main", "release/*"}}
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr     *github.PullRequest
+		add    bool
+		delete bool
+	}{
+		{newPR("main", "feature"), true, false},
+		{newPR("release/1.0", "fix"), true, false},
+		{newPR("main", "dependabot/npm"), false, false},
+		{newPR("feature/big", "feature/big-part"), false, true},
+	}
+
+	for _, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %s <- %s, got %t", want, tt.pr.BaseRefName, tt.pr.HeadRefName, got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, time.Now()); want != got {
+			t.Fatalf("Expected delete %t for %s <- %s, got %t", want, tt.pr.BaseRefName, tt.pr.HeadRefName, got)
+		}
+	}
+}
+
+func TestPullRequestAgeFilters(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	newPR := func(created, updated time.Duration) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen, CreatedAt: now.Add(-created), UpdatedAt: now.Add(-updated)}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.createdWithin = 30 * day
+	job.pullRequests.delete.staleAfter = 60 * day
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr     *github.PullRequest
+		add    bool
+		delete bool
+	}{
+		{newPR(1*day, 1*day), true, false},
+		{newPR(45*day, 1*day), false, false},
+		{newPR(400*day, 90*day), false, true},
+	}
+
+	for i, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %d, got %t", want, i, got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, now); want != got {
+			t.Fatalf("Expected delete %t for %d, got %t", want, i, got)
+		}
+	}
+}
+
+func TestPullRequestReviewFilters(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newPR := func(decision github.ReviewDecision, requested bool, reviews ...github.ReviewState) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen, ReviewDecision: decision}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		if requested {
+			pr.ReviewRequests.Nodes = append(pr.ReviewRequests.Nodes, github.ReviewRequest{ID: "request"})
+		}
+		for _, state := range reviews {
+			pr.LatestReviews.Nodes = append(pr.LatestReviews.Nodes, github.Review{State: state})
+		}
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.reviewDecisions = []github.ReviewDecision{github.ReviewDecisionReviewRequired}
+	job.pullRequests.delete.reviewDecisions = []github.ReviewDecision{github.ReviewDecisionApproved}
+
+	requested := false
+	when := configPullRequestCondition{reviews: []github.ReviewState{github.ReviewStateChangesRequested}, reviewRequested: &requested}
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr      *github.PullRequest
+		add     bool
+		delete  bool
+		matches bool
+	}{
+		{newPR(github.ReviewDecisionReviewRequired, true), true, false, false},
+		{newPR(github.ReviewDecisionApproved, false, github.ReviewStateApproved), false, true, false},
+		{newPR(github.ReviewDecisionChangesRequested, false, github.ReviewStateCommented, github.ReviewStateChangesRequested), false, false, true},
+		{newPR(github.ReviewDecisionChangesRequested, true, github.ReviewStateChangesRequested), false, false, false},
+	}
+
+	for i, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %d, got %t", want, i, got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, now); want != got {
+			t.Fatalf("Expected delete %t for %d, got %t", want, i, got)
+		}
+		if want, got := tt.matches, when.matches(tt.pr); want != got {
+			t.Fatalf("Expected match %t for %d, got %t", want, i, got)
+		}
+	}
+}
+
+func TestPullRequestReviewConditions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newPR := func(requested bool, reviews ...github.ReviewState) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		if requested {
+			pr.ReviewRequests.Nodes = append(pr.ReviewRequests.Nodes, github.ReviewRequest{ID: "request"})
+		}
+		for _, state := range reviews {
+			pr.LatestReviews.Nodes = append(pr.LatestReviews.Nodes, github.Review{State: state})
+		}
+		return pr
+	}
+
+	// Add pull requests waiting on reviewers after a comment
+	// and delete approved ones or ones nobody is asked to review.
+	requested, notRequested := true, false
+	job := configJob{}
+	job.pullRequests.add.reviews = []github.ReviewState{github.ReviewStateCommented}
+	job.pullRequests.add.reviewRequested = &requested
+	job.pullRequests.delete.reviews = []github.ReviewState{github.ReviewStateApproved}
+	job.pullRequests.delete.reviewRequested = &notRequested
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr     *github.PullRequest
+		add    bool
+		delete bool
+	}{
+		{newPR(true, github.ReviewStateCommented), true, false},
+		{newPR(true), false, false},
+		{newPR(false, github.ReviewStateCommented), false, true},
+		{newPR(true, github.ReviewStateApproved), false, true},
+	}
+
+	for i, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %d, got %t", want, i, got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, now); want != got {
+			t.Fatalf("Expected delete %t for %d, got %t", want, i, got)
+		}
+	}
+
+	if want, got := "has no pending review requests", deleteReason(job, newPR(false), now); want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestPullRequestCheckFilters(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newPR := func(state github.CheckState) *github.PullRequest {
+		pr := &github.PullRequest{State: github.PullRequestStateOpen}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		if state != "" {
+			pr.Commits.Nodes = []github.PullRequestCommit{
+				{Commit: github.Commit{StatusCheckRollup: &github.StatusCheckRollup{State: state}}},
+			}
+		}
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.checks = []github.CheckState{github.CheckStateSuccess, github.CheckStatePending}
+	job.pullRequests.delete.checks = []github.CheckState{github.CheckStateFailure}
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr     *github.PullRequest
+		add    bool
+		delete bool
+	}{
+		{newPR(""), false, false},
+		{newPR(github.CheckStateSuccess), true, false},
+		{newPR(github.CheckStatePending), true, false},
+		{newPR(github.CheckStateFailure), false, true},
+		{newPR(github.CheckStateError), false, false},
+	}
+
+	for _, tt := range tests {
+		add, err := isAddCandidate(ctx, job, authors, &reviewers{}, tt.pr, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.add, add; want != got {
+			t.Fatalf("Expected add %t for %q, got %t", want, tt.pr.CheckState(), got)
+		}
+		if want, got := tt.delete, isDeleteCandidate(job, tt.pr, now); want != got {
+			t.Fatalf("Expected delete %t for %q, got %t", want, tt.pr.CheckState(), got)
+		}
+	}
+}
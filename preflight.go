@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// requiredScopes are the classic personal access token scopes the tool needs
+// and the broader scopes that imply them.
+var requiredScopes = []struct {
+	scope   string
+	implied []string
+}{
+	{"repo", nil},
+	{"read:org", []string{"write:org", "admin:org"}},
+	{"read:user", []string{"user"}},
+	{"project", nil},
+}
+
+// preflight checks that the token can read the configured repositories and teams
+// and update the projects of all jobs, and writes a report of everything that's missing.
+// Missing access fails the check unless it's a dry run that makes no changes.
+// Scopes are only checked if reported, i.e. for classic personal access tokens.
+func preflight(ctx context.Context, w io.Writer, client githubClient, cfg config, scopes []string) error {
+	problems := preflightProblems(ctx, client, cfg, scopes)
+
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "Preflight check: OK")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Preflight check:")
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  - %s\n", problem)
+	}
+
+	if cfg.dryRun {
+		fmt.Fprintln(w, "  Continuing in dry run mode")
+		return nil
+	}
+
+	return fmt.Errorf("preflight check failed: %d problems found", len(problems))
+}
+
+func preflightProblems(ctx context.Context, client githubClient, cfg config, scopes []string) []string {
+	var problems []string
+
+	if scopes != nil {
+		for _, required := range requiredScopes {
+			if !slices.Contains(scopes, required.scope) && !slices.ContainsFunc(required.implied, func(scope string) bool {
+				return slices.Contains(scopes, scope)
+			}) {
+				problems = append(problems, fmt.Sprintf("token is missing the %s scope", required.scope))
+			}
+		}
+	}
+
+	var (
+		projects = make(map[configProject]bool)
+		repos    = make(map[configRepo]bool)
+		teams    = make(map[configTeam]bool)
+	)
+
+	checkTeam := func(org, slug string) {
+		team := configTeam{owner: org, name: slug}
+		if teams[team] {
+			return
+		}
+		teams[team] = true
+
+		found, err := client.GetTeam(ctx, org, slug)
+		if err != nil || found == nil {
+			problems = append(problems, fmt.Sprintf("can't read team %s/%s: %s", org, slug, errOrNotFound(err)))
+		}
+	}
+
+	for _, job := range cfg.jobs {
+		if !projects[job.project] {
+			projects[job.project] = true
+
+			project, err := client.GetProject(ctx, job.project.owner, job.project.ownerType, job.project.number)
+			switch {
+			case err != nil || project == nil:
+				problems = append(problems, fmt.Sprintf("can't read project %s/%d: %s", job.project.owner, job.project.number, errOrNotFound(err)))
+			case !project.ViewerCanUpdate:
+				problems = append(problems, fmt.Sprintf("can't update project %s/%d", job.project.owner, job.project.number))
+			}
+		}
+
+		// Patterns and topics are resolved at runtime and only find what's readable.
+		for _, source := range job.repoSources {
+			switch source.kind {
+			case repoSourceName:
+				repo := configRepo{source.owner, source.name}
+				if repos[repo] {
+					continue
+				}
+				repos[repo] = true
+
+				found, err := client.GetRepository(ctx, repo.owner, repo.name)
+				if err != nil || found == nil {
+					problems = append(problems, fmt.Sprintf("can't read repository %s/%s: %s", repo.owner, repo.name, errOrNotFound(err)))
+				}
+			case repoSourceTeam:
+				checkTeam(source.owner, source.name)
+			}
+		}
+
+		for _, rules := range []configAuthorRules{job.authors.include, job.authors.exclude, job.reviewers.include} {
+			for _, team := range rules.teams {
+				checkTeam(team.owner, team.name)
+			}
+		}
+	}
+
+	return problems
+}
+
+func errOrNotFound(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "not found"
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestParseScopes(t *testing.T) {
+	header := http.Header{}
+	if got := parseScopes(header); got != nil {
+		t.Fatalf("Expected nil, got %v", got)
+	}
+
+	header.Set("X-OAuth-Scopes", "")
+	if got := parseScopes(header); got == nil || len(got) != 0 {
+		t.Fatalf("Expected no scopes, got %v", got)
+	}
+
+	header.Set("X-OAuth-Scopes", "repo, read:org,project")
+	if want, got := "repo read:org project", strings.Join(parseScopes(header), " "); want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	ctx := context.Background()
+
+	var teamCalls int
+	client := &fakeGithubClient{
+		GetProjectFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
+			if number == 2 {
+				return nil, errors.New("project not found")
+			}
+			return &github.Project{ID: "project", ViewerCanUpdate: number == 1}, nil
+		},
+		GetRepositoryFunc: func(ctx context.Context, owner, name string) (*github.Repository, error) {
+			if name == "private" {
+				return nil, errors.New("repository not found")
+			}
+			return &github.Repository{Name: name}, nil
+		},
+		GetTeamFunc: func(ctx context.Context, org, team string) (*github.Team, error) {
+			teamCalls++
+			if team == "secret" {
+				return nil, nil
+			}
+			return &github.Team{Slug: team}, nil
+		},
+	}
+
+	job := func(number int) configJob {
+		job := configJob{name: "job", project: configProject{owner: "org", number: number}}
+		job.repoSources = []configRepoSource{
+			{kind: repoSourceName, owner: "org", name: "repo"},
+			{kind: repoSourceName, owner: "org", name: "private"},
+			{kind: repoSourcePattern, owner: "org", name: "service-*"},
+			{kind: repoSourceTeam, owner: "org", name: "backend"},
+		}
+		job.authors.include.teams = []configTeam{{owner: "org", name: "backend"}, {owner: "org", name: "secret"}}
+		job.reviewers.include.teams = []configTeam{{owner: "org", name: "backend"}}
+		return job
+	}
+	cfg := config{jobs: []configJob{job(1), job(1), job(2), job(3)}}
+
+	var out bytes.Buffer
+	err := preflight(ctx, &out, client, cfg, []string{"repo", "admin:org", "read:project"})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	want := `Preflight check:
+  - token is missing the read:user scope
+  - token is missing the project scope
+  - can't read repository org/private: repository not found
+  - can't read team org/secret: not found
+  - can't read project org/2: project not found
+  - can't update project org/3
+`
+	if got := out.String(); want != got {
+		t.Fatalf("Expected\n%s\ngot\n%s", want, got)
+	}
+	if want, got := 2, teamCalls; want != got {
+		t.Fatalf("Expected %d team calls, got %d", want, got)
+	}
+
+	// A dry run only reports the problems.
+	cfg.dryRun = true
+	out.Reset()
+	if err := preflight(ctx, &out, client, cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Continuing in dry run mode") {
+		t.Fatalf("Expected a dry run note, got\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := preflight(ctx, &out, client, config{jobs: []configJob{{project: configProject{owner: "org", number: 1}}}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "Preflight check: OK\n", out.String(); want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// outputFormat defines how the sync results are reported.
+type outputFormat string
+
+const (
+	outputText   outputFormat = "text"   // Human readable progress only.
+	outputJSON   outputFormat = "json"   // A single document per sync.
+	outputNDJSON outputFormat = "ndjson" // A record per line.
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch format := outputFormat(s); format {
+	case outputText, outputJSON, outputNDJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid output format: %s", s)
+}
+
+// Pull request and issue record decisions.
+const (
+	decisionAdd     = "add"
+	decisionExists  = "exists"
+	decisionSkip    = "skip"
+	decisionUpdate  = "update"
+	decisionKeep    = "keep"
+	decisionDelete  = "delete"
+	decisionArchive = "archive"
+)
+
+// Skip and keep reasons besides the matched add and delete rules.
+const (
+	reasonAuthorNotIncluded  = "author is not included"
+	reasonNoIncludedReviewer = "no included reviewer"
+	reasonAlreadyArchived    = "already archived"
+)
+
+// Action results.
+const (
+	resultOK     = "ok"
+	resultDryRun = "dry-run"
+	resultError  = "error"
+)
+
+// pullRequestRecord is a pull request evaluated in one of the add, update or delete phases of a sync.
+type pullRequestRecord struct {
+	Type     string                  `json:"type"` // Always pull_request.
+	Job      string                  `json:"job"`
+	Phase    string                  `json:"phase"` // add, update or delete.
+	URL      string                  `json:"url"`
+	Author   string                  `json:"author"`
+	Title    string                  `json:"title"`
+	State    github.PullRequestState `json:"state"`
+	Draft    bool                    `json:"draft"`
+	Decision string                  `json:"decision"`
+	Reason   string                  `json:"reason,omitempty"`
+	Result   string                  `json:"result,omitempty"` // Only if an action was taken.
+	Error    string                  `json:"error,omitempty"`
+}
+
+// issueRecord is an issue evaluated in one of the add or delete phases of a sync.
+type issueRecord struct {
+	Type     string            `json:"type"` // Always issue.
+	Job      string            `json:"job"`
+	Phase    string            `json:"phase"` // add or delete.
+	URL      string            `json:"url"`
+	Author   string            `json:"author"`
+	Title    string            `json:"title"`
+	State    github.IssueState `json:"state"`
+	Decision string            `json:"decision"`
+	Reason   string            `json:"reason,omitempty"`
+	Result   string            `json:"result,omitempty"` // Only if an action was taken.
+	Error    string            `json:"error,omitempty"`
+}
+
+// summaryRecord closes the records of a sync.
+type summaryRecord struct {
+	Type          string       `json:"type"` // Always summary.
+	Jobs          []jobSummary `json:"jobs"`
+	Added         int          `json:"added"`
+	Updated       int          `json:"updated"`
+	Deleted       int          `json:"deleted"`
+	IssuesAdded   int          `json:"issuesAdded"`
+	IssuesDeleted int          `json:"issuesDeleted"`
+	Failed        int          `json:"failed"`
+	StartedAt     time.Time    `json:"startedAt"`
+	Duration      float64      `json:"durationSeconds"`
+	RateLimit     int          `json:"rateLimitUsed"` // GraphQL rate limit points.
+}
+
+type jobSummary struct {
+	Name          string `json:"name"`
+	Added         int    `json:"added"`
+	Updated       int    `json:"updated"`
+	Deleted       int    `json:"deleted"`
+	IssuesAdded   int    `json:"issuesAdded,omitempty"`
+	IssuesDeleted int    `json:"issuesDeleted,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// reporter writes machine readable records of a sync.
+// A nil reporter or the text format reports nothing.
+type reporter struct {
+	format  outputFormat
+	w       io.Writer
+	records []pullRequestRecord // Buffered until the summary in the json format.
+	issues  []issueRecord       // Buffered until the summary in the json format.
+}
+
+func newReporter(format outputFormat, w io.Writer) *reporter {
+	return &reporter{format: format, w: w}
+}
+
+func (r *reporter) enabled() bool {
+	return r != nil && r.format != outputText
+}
+
+// pullRequest reports a decision made on the pull request and the result of the action taken, if any.
+func (r *reporter) pullRequest(job configJob, phase string, pr *github.PullRequest, decision, reason, result string, err error) {
+	if !r.enabled() {
+		return
+	}
+
+	record := pullRequestRecord{
+		Type:     "pull_request",
+		Job:      job.name,
+		Phase:    phase,
+		URL:      pr.URL,
+		Author:   pr.Author.Login,
+		Title:    pr.Title,
+		State:    pr.State,
+		Draft:    pr.IsDraft,
+		Decision: decision,
+		Reason:   reason,
+		Result:   result,
+	}
+	if err != nil {
+		record.Result, record.Error = resultError, err.Error()
+	}
+
+	if r.format == outputJSON {
+		r.records = append(r.records, record)
+		return
+	}
+	r.write(record)
+}
+
+// issue reports a decision made on the issue and the result of the action taken, if any.
+func (r *reporter) issue(job configJob, phase string, issue *github.Issue, decision, reason, result string, err error) {
+	if !r.enabled() {
+		return
+	}
+
+	record := issueRecord{
+		Type:     "issue",
+		Job:      job.name,
+		Phase:    phase,
+		URL:      issue.URL,
+		Author:   issue.Author.Login,
+		Title:    issue.Title,
+		State:    issue.State,
+		Decision: decision,
+		Reason:   reason,
+		Result:   result,
+	}
+	if err != nil {
+		record.Result, record.Error = resultError, err.Error()
+	}
+
+	if r.format == outputJSON {
+		r.issues = append(r.issues, record)
+		return
+	}
+	r.write(record)
+}
+
+// summary reports the summary of a sync and flushes buffered records.
+func (r *reporter) summary(startedAt time.Time, results []jobResult, rateLimitUsed int) {
+	if !r.enabled() {
+		return
+	}
+
+	summary := summaryRecord{
+		Type:      "summary",
+		Jobs:      make([]jobSummary, 0, len(results)),
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt).Seconds(),
+		RateLimit: rateLimitUsed,
+	}
+	for _, result := range results {
+		job := jobSummary{
+			Name:          result.name,
+			Added:         result.added,
+			Updated:       result.updated,
+			Deleted:       result.deleted,
+			IssuesAdded:   result.issuesAdded,
+			IssuesDeleted: result.issuesDeleted,
+		}
+		if result.err != nil {
+			job.Error = result.err.Error()
+			summary.Failed++
+		}
+		summary.Jobs = append(summary.Jobs, job)
+		summary.Added += result.added
+		summary.Updated += result.updated
+		summary.Deleted += result.deleted
+		summary.IssuesAdded += result.issuesAdded
+		summary.IssuesDeleted += result.issuesDeleted
+	}
+
+	if r.format == outputNDJSON {
+		r.write(summary)
+		return
+	}
+
+	records, issues := r.records, r.issues
+	if records == nil {
+		records = []pullRequestRecord{}
+	}
+	if issues == nil {
+		issues = []issueRecord{}
+	}
+	r.write(struct {
+		PullRequests []pullRequestRecord `json:"pullRequests"`
+		Issues       []issueRecord       `json:"issues"`
+		Summary      summaryRecord       `json:"summary"`
+	}{records, issues, summary})
+	r.records, r.issues = nil, nil
+}
+
+func (r *reporter) write(v any) {
+	if err := json.NewEncoder(r.w).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %s\n", err)
+	}
+}
+
+// actionResult returns the result of an action that succeeded.
+func actionResult(cfg config) string {
+	if cfg.dryRun {
+		return resultDryRun
+	}
+	return resultOK
+}
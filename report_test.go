@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, s := range []string{"text", "json", "ndjson"} {
+		if _, err := parseOutputFormat(s); err != nil {
+			t.Fatalf("Unexpected error for %s: %s", s, err)
+		}
+	}
+	if _, err := parseOutputFormat("yaml"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestReporterNDJSON(t *testing.T) {
+	var out bytes.Buffer
+	report := newReporter(outputNDJSON, &out)
+
+	job := configJob{name: "job1"}
+	pr := &github.PullRequest{URL: "https://github.com/org/repo/pull/1", State: github.PullRequestStateOpen}
+	pr.Author.Login = "user"
+
+	report.pullRequest(job, "add", pr, decisionAdd, "", resultOK, nil)
+	report.pullRequest(job, "delete", pr, decisionDelete, "state is one of [MERGED]", "", errors.New("boom"))
+	report.summary(time.Now(), []jobResult{{name: "job1", added: 1, issuesAdded: 2}, {name: "job2", err: errors.New("failed")}}, 42)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if want, got := 3, len(lines); want != got {
+		t.Fatalf("Expected %d lines, got %d", want, got)
+	}
+
+	var record pullRequestRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := (pullRequestRecord{
+		Type:     "pull_request",
+		Job:      "job1",
+		Phase:    "delete",
+		URL:      pr.URL,
+		Author:   "user",
+		State:    github.PullRequestStateOpen,
+		Decision: decisionDelete,
+		Reason:   "state is one of [MERGED]",
+		Result:   resultError,
+		Error:    "boom",
+	}), record; want != got {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	var summary summaryRecord
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "summary", summary.Type; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 1, summary.Added; want != got {
+		t.Fatalf("Expected %d added, got %d", want, got)
+	}
+	if want, got := 2, summary.IssuesAdded; want != got {
+		t.Fatalf("Expected %d issues added, got %d", want, got)
+	}
+	if want, got := 1, summary.Failed; want != got {
+		t.Fatalf("Expected %d failed, got %d", want, got)
+	}
+	if want, got := 42, summary.RateLimit; want != got {
+		t.Fatalf("Expected %d rate limit points, got %d", want, got)
+	}
+}
+
+func TestReporterJSON(t *testing.T) {
+	var out bytes.Buffer
+	report := newReporter(outputJSON, &out)
+
+	pr := &github.PullRequest{URL: "https://github.com/org/repo/pull/1"}
+	report.pullRequest(configJob{name: "job1"}, "add", pr, decisionExists, "", "", nil)
+	report.issue(configJob{name: "job1"}, "add", &github.Issue{URL: "https://github.com/org/repo/issues/2"}, decisionAdd, "", resultOK, nil)
+	if out.Len() > 0 {
+		t.Fatal("Expected records to be buffered until the summary")
+	}
+	report.summary(time.Now(), []jobResult{{name: "job1"}}, 0)
+
+	var doc struct {
+		PullRequests []pullRequestRecord `json:"pullRequests"`
+		Issues       []issueRecord       `json:"issues"`
+		Summary      summaryRecord       `json:"summary"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 1, len(doc.PullRequests); want != got {
+		t.Fatalf("Expected %d records, got %d", want, got)
+	}
+	if want, got := decisionExists, doc.PullRequests[0].Decision; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 1, len(doc.Issues); want != got {
+		t.Fatalf("Expected %d issue records, got %d", want, got)
+	}
+	if want, got := "issue", doc.Issues[0].Type; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want, got := 1, len(doc.Summary.Jobs); want != got {
+		t.Fatalf("Expected %d jobs, got %d", want, got)
+	}
+}
+
+func TestAddNewPullRequestsReport(t *testing.T) {
+	ctx := context.Background()
+
+	newPR := func(number int, draft bool, author, reviewer string) *github.PullRequest {
+		pr := &github.PullRequest{ID: "PR", Number: number, State: github.PullRequestStateOpen, IsDraft: draft}
+		pr.Author.Type, pr.Author.Login = github.AuthorTypeUser, author
+		pr.Repository.Owner.Login, pr.Repository.Name = "org", "repo"
+		if reviewer != "" {
+			pr.LatestReviews.Nodes = []github.Review{{State: github.ReviewStateApproved}}
+			pr.LatestReviews.Nodes[0].Author.Login = reviewer
+		}
+		return pr
+	}
+	client := &fakeGithubClient{
+		GetRepositoryPullRequestsFunc: func(ctx context.Context, owner, name string, states []github.PullRequestState) iter.Seq2[*github.PullRequest, error] {
+			return seqOf(
+				*newPR(1, false, "user", "reviewer"),
+				*newPR(2, false, "user", "reviewer"),
+				*newPR(3, true, "user", "reviewer"),
+				*newPR(4, false, "outsider", "reviewer"),
+				*newPR(5, false, "user", "stranger"),
+			)
+		},
+	}
+
+	var out, progress bytes.Buffer
+	cfg := config{dryRun: true, report: newReporter(outputNDJSON, &out), progress: &progress}
+	job := configJob{name: "job1", repos: []configRepo{{owner: "org", name: "repo"}}}
+	projectPRs := map[prKey]*github.PullRequest{{"org", "repo", 2}: newPR(2, false, "user", "reviewer")}
+
+	memberships := newMemberships(client, false)
+	authors, err := NewAuthors(ctx, memberships, configAuthors{exclude: configAuthorRules{users: []string{"outsider"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviewers, err := NewReviewers(ctx, memberships, configReviewers{include: configAuthorRules{users: []string{"reviewer"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := addNewPullRequests(ctx, client, cfg, job, authors, reviewers, &github.Project{ID: "project"}, projectPRs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var decisions []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record pullRequestRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		decisions = append(decisions, record.Decision+" "+record.Result+" "+record.Reason)
+	}
+
+	want := []string{
+		"add dry-run ",
+		"exists  already in the project",
+		"skip  failed: not a draft",
+		"skip  author is not included",
+		"skip  no included reviewer",
+	}
+	if strings.Join(want, "\n") != strings.Join(decisions, "\n") {
+		t.Fatalf("Expected %q, got %q", want, decisions)
+	}
+
+	// The progress is kept apart from the records.
+	if want, got := "Checking for pull requests to add:", progress.String(); !strings.HasPrefix(got, want) {
+		t.Fatalf("Expected %q progress, got %q", want, got)
+	}
+}
+
+func TestIssuesReport(t *testing.T) {
+	ctx := context.Background()
+
+	newIssue := func(number int, login string, authorType github.AuthorType, state github.IssueState) *github.Issue {
+		issue := &github.Issue{ID: "issue", Number: number, State: state}
+		issue.Author.Type, issue.Author.Login = authorType, login
+		issue.Repository.Owner.Login, issue.Repository.Name = "org", "repo"
+		return issue
+	}
+	client := &fakeGithubClient{
+		GetRepositoryIssuesFunc: func(ctx context.Context, owner, name string, states []github.IssueState) iter.Seq2[*github.Issue, error] {
+			return seqOf(
+				*newIssue(1, "user", github.AuthorTypeUser, github.IssueStateOpen),
+				*newIssue(2, "user", github.AuthorTypeUser, github.IssueStateOpen),
+				*newIssue(3, "bot", github.AuthorTypeBot, github.IssueStateOpen),
+				*newIssue(4, "outsider", github.AuthorTypeUser, github.IssueStateOpen),
+			)
+		},
+	}
+
+	var out bytes.Buffer
+	cfg := config{dryRun: true, report: newReporter(outputNDJSON, &out), progress: &bytes.Buffer{}}
+	job := configJob{name: "job1", repos: []configRepo{{owner: "org", name: "repo"}}}
+	job.issues.add.states = []github.IssueState{github.IssueStateOpen}
+	job.issues.delete.states = []github.IssueState{github.IssueStateClosed}
+
+	authors, err := NewAuthors(ctx, newMemberships(client, false), configAuthors{exclude: configAuthorRules{users: []string{"outsider"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := &github.Project{ID: "project"}
+	projectIssues := map[prKey]*github.Issue{{"org", "repo", 2}: newIssue(2, "user", github.AuthorTypeUser, github.IssueStateOpen)}
+	if _, err := addNewIssues(ctx, client, cfg, job, authors, project, projectIssues, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	projectIssues = map[prKey]*github.Issue{{"org", "repo", 5}: newIssue(5, "user", github.AuthorTypeUser, github.IssueStateClosed)}
+	if _, err := deleteCompletedIssues(ctx, client, cfg, job, authors, project, projectIssues); err != nil {
+		t.Fatal(err)
+	}
+
+	var decisions []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record issueRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "issue", record.Type; want != got {
+			t.Fatalf("Expected %s, got %s", want, got)
+		}
+		decisions = append(decisions, record.Phase+" "+record.Decision+" "+record.Result+" "+record.Reason)
+	}
+
+	want := []string{
+		"add add dry-run ",
+		"add exists  already in the project",
+		"add skip  failed: author is a user",
+		"add skip  author is not included",
+		"delete delete dry-run state is one of [CLOSED]",
+	}
+	if strings.Join(want, "\n") != strings.Join(decisions, "\n") {
+		t.Fatalf("Expected %q, got %q", want, decisions)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+type repoSourceType int
+
+const (
+	repoSourceName    repoSourceType = iota // <owner>/<name>
+	repoSourcePattern                       // <owner>/<glob>
+	repoSourceTopic                         // topic:[<owner>/]<topic>
+	repoSourceTeam                          // team:<org>/<team>
+)
+
+// configRepoSource is a repository or a set of repositories resolved at runtime.
+type configRepoSource struct {
+	kind  repoSourceType
+	owner string
+	name  string // A repository name, a glob pattern, a topic or a team slug.
+}
+
+func (s configRepoSource) String() string {
+	switch s.kind {
+	case repoSourceTopic:
+		if s.owner == "" {
+			return "topic:" + s.name
+		}
+		return "topic:" + s.owner + "/" + s.name
+	case repoSourceTeam:
+		return "team:" + s.owner + "/" + s.name
+	default:
+		return s.owner + "/" + s.name
+	}
+}
+
+// parseRepoSource parses a repository entry in one of the following forms:
+//   - <owner>/<name> a single repository
+//   - <owner>/<glob> repositories of an organization or a user matching the glob pattern, e.g. myorg/service-*
+//   - topic:[<owner>/]<topic> repositories with the topic owned by the owner, or the project owner if omitted
+//   - team:<org>/<team> repositories the team has access to
+func parseRepoSource(repo string) (configRepoSource, error) {
+	var source configRepoSource
+
+	value := repo
+	switch {
+	case strings.HasPrefix(repo, "topic:"):
+		source.kind = repoSourceTopic
+		value = strings.TrimPrefix(repo, "topic:")
+		if !strings.Contains(value, "/") {
+			if value == "" {
+				return configRepoSource{}, fmt.Errorf("invalid repository: %s", repo)
+			}
+			source.name = value
+			return source, nil
+		}
+	case strings.HasPrefix(repo, "team:"):
+		source.kind = repoSourceTeam
+		value = strings.TrimPrefix(repo, "team:")
+	}
+
+	owner, name, ok := strings.Cut(value, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return configRepoSource{}, fmt.Errorf("invalid repository: %s", repo)
+	}
+	source.owner, source.name = owner, name
+
+	if source.kind == repoSourceName && strings.ContainsAny(name, "*?[") {
+		if _, err := path.Match(name, ""); err != nil {
+			return configRepoSource{}, fmt.Errorf("invalid repository pattern: %s: %w", repo, err)
+		}
+		source.kind = repoSourcePattern
+	}
+
+	return source, nil
+}
+
+// topicOwner returns the owner the topic source is limited to.
+// A topic without an owner is limited to the project owner rather than searched across all of GitHub.
+func topicOwner(job configJob, source configRepoSource) string {
+	if source.owner != "" {
+		return source.owner
+	}
+	return job.project.owner
+}
+
+// resolveRepos expands the job's repository sources into a list of repositories
+// excluding the ones matching job.excludeRepos.
+// Archived and fork repositories are skipped unless explicitly listed or allowed.
+func resolveRepos(ctx context.Context, client githubClient, cfg config, job configJob) ([]configRepo, error) {
+	var (
+		repos []configRepo
+		seen  = make(map[configRepo]bool)
+	)
+
+	add := func(repo configRepo) {
+		if seen[repo] {
+			return
+		}
+		seen[repo] = true
+
+		for _, pattern := range job.excludeRepos {
+			if matched, _ := path.Match(pattern, repo.owner+"/"+repo.name); matched {
+				if cfg.verbose {
+					fmt.Fprintf(cfg.out(), "  - %s/%s EXCLUDED\n", repo.owner, repo.name)
+				}
+				return
+			}
+		}
+
+		repos = append(repos, repo)
+	}
+
+	discover := func(source configRepoSource, found iter.Seq2[*github.Repository, error]) error {
+		if cfg.verbose {
+			fmt.Fprintf(cfg.out(), "Discovering repositories for %s\n", source)
+		}
+		for repo, err := range found {
+			if err != nil {
+				return fmt.Errorf("error discovering repositories for %s: %w", source, err)
+			}
+
+			if source.kind == repoSourcePattern {
+				if matched, _ := path.Match(source.name, repo.Name); !matched {
+					continue
+				}
+			}
+
+			if repo.IsArchived && !job.archived {
+				if cfg.verbose {
+					fmt.Fprintf(cfg.out(), "  - %s/%s ARCHIVED\n", repo.Owner.Login, repo.Name)
+				}
+				continue
+			}
+			if repo.IsFork && !job.forks {
+				if cfg.verbose {
+					fmt.Fprintf(cfg.out(), "  - %s/%s FORK\n", repo.Owner.Login, repo.Name)
+				}
+				continue
+			}
+
+			add(configRepo{repo.Owner.Login, repo.Name})
+		}
+		return nil
+	}
+
+	for _, source := range job.repoSources {
+		var err error
+		switch source.kind {
+		case repoSourceName:
+			add(configRepo{source.owner, source.name})
+		case repoSourcePattern:
+			err = discover(source, client.GetOwnerRepositories(ctx, source.owner))
+		case repoSourceTopic:
+			// Search excludes forks by default.
+			query := "fork:true topic:" + source.name + " user:" + topicOwner(job, source)
+			err = discover(source, client.SearchRepositories(ctx, query))
+		case repoSourceTeam:
+			err = discover(source, client.GetTeamRepositories(ctx, source.owner, source.name))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories found")
+	}
+
+	return repos, nil
+}
+
+// includesRepo reports whether the repository is one of the job's repositories
+// applying the same rules as resolveRepos without resolving all of them.
+// Only team sources require API calls.
+func includesRepo(ctx context.Context, client githubClient, job configJob, repo *github.Repository, topics []string) (bool, error) {
+	for _, pattern := range job.excludeRepos {
+		if matched, _ := path.Match(pattern, repo.Owner.Login+"/"+repo.Name); matched {
+			return false, nil
+		}
+	}
+
+	discoverable := (!repo.IsArchived || job.archived) && (!repo.IsFork || job.forks)
+
+	for _, source := range job.repoSources {
+		switch source.kind {
+		case repoSourceName:
+			if strings.EqualFold(source.owner, repo.Owner.Login) && strings.EqualFold(source.name, repo.Name) {
+				return true, nil
+			}
+		case repoSourcePattern:
+			if !discoverable || !strings.EqualFold(source.owner, repo.Owner.Login) {
+				continue
+			}
+			if matched, _ := path.Match(source.name, repo.Name); matched {
+				return true, nil
+			}
+		case repoSourceTopic:
+			if !discoverable || !strings.EqualFold(topicOwner(job, source), repo.Owner.Login) {
+				continue
+			}
+			if slices.Contains(topics, source.name) {
+				return true, nil
+			}
+		case repoSourceTeam:
+			if !discoverable {
+				continue
+			}
+			for teamRepo, err := range client.GetTeamRepositories(ctx, source.owner, source.name) {
+				if err != nil {
+					return false, fmt.Errorf("error discovering repositories for %s: %w", source, err)
+				}
+				if strings.EqualFold(teamRepo.Owner.Login, repo.Owner.Login) && strings.EqualFold(teamRepo.Name, repo.Name) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestParseRepoSource(t *testing.T) {
+	tests := []struct {
+		repo    string
+		want    configRepoSource
+		wantErr bool
+	}{
+		{repo: "org/repo", want: configRepoSource{kind: repoSourceName, owner: "org", name: "repo"}},
+		{repo: "org/*", want: configRepoSource{kind: repoSourcePattern, owner: "org", name: "*"}},
+		{repo: "org/service-*", want: configRepoSource{kind: repoSourcePattern, owner: "org", name: "service-*"}},
+		{repo: "topic:backend", want: configRepoSource{kind: repoSourceTopic, name: "backend"}},
+		{repo: "topic:org/backend", want: configRepoSource{kind: repoSourceTopic, owner: "org", name: "backend"}},
+		{repo: "team:org/platform", want: configRepoSource{kind: repoSourceTeam, owner: "org", name: "platform"}},
+		{repo: "org", wantErr: true},
+		{repo: "org/", wantErr: true},
+		{repo: "org/repo/extra", wantErr: true},
+		{repo: "org/[", wantErr: true},
+		{repo: "topic:", wantErr: true},
+		{repo: "team:org", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			got, err := parseRepoSource(tt.repo)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.want; want != got {
+				t.Fatalf("Expected %+v, got %+v", want, got)
+			}
+			if want, got := tt.repo, got.String(); want != got {
+				t.Fatalf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestResolveRepos(t *testing.T) {
+	ctx := context.Background()
+
+	repos := func(repos ...github.Repository) iter.Seq2[*github.Repository, error] {
+		return func(yield func(*github.Repository, error) bool) {
+			for _, repo := range repos {
+				if !yield(&repo, nil) {
+					return
+				}
+			}
+		}
+	}
+	repo := func(owner, name string, archived, fork bool) github.Repository {
+		r := github.Repository{Name: name, IsArchived: archived, IsFork: fork}
+		r.Owner.Login = owner
+		return r
+	}
+
+	client := &fakeGithubClient{
+		GetOwnerRepositoriesFunc: func(ctx context.Context, owner string) iter.Seq2[*github.Repository, error] {
+			return repos(
+				repo("org", "service-a", false, false),
+				repo("org", "service-b", true, false),
+				repo("org", "service-c", false, true),
+				repo("org", "service-legacy", false, false),
+				repo("org", "website", false, false),
+			)
+		},
+		SearchRepositoriesFunc: func(ctx context.Context, query string) iter.Seq2[*github.Repository, error] {
+			if want, got := "fork:true topic:backend user:org", query; want != got {
+				t.Errorf("Expected query %s, got %s", want, got)
+			}
+			return repos(
+				repo("org", "api", false, false),
+				repo("org", "service-a", false, false),
+			)
+		},
+		GetTeamRepositoriesFunc: func(ctx context.Context, org, team string) iter.Seq2[*github.Repository, error] {
+			return repos(repo("org", "platform", false, false))
+		},
+	}
+
+	job := configJob{
+		repoSources: []configRepoSource{
+			{kind: repoSourceName, owner: "other", name: "repo"},
+			{kind: repoSourcePattern, owner: "org", name: "service-*"},
+			{kind: repoSourceTopic, owner: "org", name: "backend"},
+			{kind: repoSourceTeam, owner: "org", name: "platform"},
+		},
+		excludeRepos: []string{"org/*-legacy"},
+	}
+
+	got, err := resolveRepos(ctx, client, config{}, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []configRepo{
+		{"other", "repo"},
+		{"org", "service-a"},
+		{"org", "api"},
+		{"org", "platform"},
+	}
+	if !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	// Include archived and fork repositories.
+	job.archived = true
+	job.forks = true
+	job.repoSources = job.repoSources[1:2]
+
+	got, err = resolveRepos(ctx, client, config{}, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = []configRepo{
+		{"org", "service-a"},
+		{"org", "service-b"},
+		{"org", "service-c"},
+	}
+	if !slices.Equal(want, got) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestResolveReposTopicOwner(t *testing.T) {
+	ctx := context.Background()
+
+	var queries []string
+	client := &fakeGithubClient{
+		SearchRepositoriesFunc: func(ctx context.Context, query string) iter.Seq2[*github.Repository, error] {
+			queries = append(queries, query)
+			repo := github.Repository{Name: "api"}
+			repo.Owner.Login = "org"
+			return seqOf(repo)
+		},
+	}
+
+	job := configJob{
+		project: configProject{owner: "org", number: 1},
+		repoSources: []configRepoSource{
+			{kind: repoSourceTopic, name: "backend"},
+			{kind: repoSourceTopic, owner: "other", name: "frontend"},
+		},
+	}
+
+	if _, err := resolveRepos(ctx, client, config{}, job); err != nil {
+		t.Fatal(err)
+	}
+
+	// The search is never across all of GitHub.
+	want := []string{"fork:true topic:backend user:org", "fork:true topic:frontend user:other"}
+	if !slices.Equal(want, queries) {
+		t.Fatalf("Expected %q, got %q", want, queries)
+	}
+
+	// A repository of another owner with the topic isn't included.
+	repo := &github.Repository{Name: "api"}
+	repo.Owner.Login = "stranger"
+	included, err := includesRepo(ctx, client, job, repo, []string{"backend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included {
+		t.Fatal("Expected the repository to be excluded")
+	}
+}
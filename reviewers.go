@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// configReviewers selects pull requests by the requested and actual reviewers.
+type configReviewers struct {
+	include configAuthorRules // Only users and teams.
+}
+
+type reviewerResolver interface {
+	Resolve(ctx context.Context, pr *github.PullRequest) (bool, error)
+}
+
+type reviewers struct {
+	memberships *memberships
+	rules       configReviewers
+	included    map[string]bool
+}
+
+func NewReviewers(ctx context.Context, memberships *memberships, rules configReviewers) (*reviewers, error) {
+	r := &reviewers{
+		memberships: memberships,
+		rules:       rules,
+		included:    make(map[string]bool),
+	}
+
+	for _, user := range rules.include.users {
+		r.included[user] = true
+	}
+
+	for _, team := range rules.include.teams {
+		if _, err := memberships.teamMembers(ctx, team); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Resolve reports whether a review of the pull request is requested from
+// or has been given by any of the included users, teams or team members.
+func (r *reviewers) Resolve(ctx context.Context, pr *github.PullRequest) (bool, error) {
+	// By default, all pull requests are included.
+	if r.rules.include.empty() {
+		return true, nil
+	}
+
+	for _, request := range pr.ReviewRequests.Nodes {
+		reviewer := request.RequestedReviewer
+		if reviewer.Type == github.ReviewerTypeTeam {
+			if slices.ContainsFunc(r.rules.include.teams, func(t configTeam) bool {
+				return t.owner == reviewer.Organization.Login && t.name == reviewer.Slug
+			}) {
+				return true, nil
+			}
+			continue
+		}
+
+		included, err := r.isIncluded(ctx, reviewer.Login)
+		if err != nil || included {
+			return included, err
+		}
+	}
+
+	for _, review := range pr.LatestReviews.Nodes {
+		included, err := r.isIncluded(ctx, review.Author.Login)
+		if err != nil || included {
+			return included, err
+		}
+	}
+
+	return false, nil
+}
+
+func (r *reviewers) isIncluded(ctx context.Context, login string) (bool, error) {
+	if login == "" {
+		return false, nil
+	}
+
+	if r.included[login] {
+		return true, nil
+	}
+
+	for _, team := range r.rules.include.teams {
+		members, err := r.memberships.teamMembers(ctx, team)
+		if err != nil {
+			return false, err
+		}
+		if members[login] {
+			if r.memberships.verbose {
+				fmt.Fprintf(r.memberships.out(), "        %s is a member of %s\n", login, team)
+			}
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
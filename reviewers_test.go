@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestReviewersResolve(t *testing.T) {
+	ctx := context.Background()
+
+	var teamCalls int
+	client := &fakeGithubClient{
+		GetTeamMembersFunc: func(ctx context.Context, owner, name string, membership github.TeamMembership) iter.Seq2[*github.User, error] {
+			teamCalls++
+			return seqOf(github.User{ID: "member", Login: "member"})
+		},
+	}
+
+	rules := configReviewers{
+		include: configAuthorRules{
+			users: []string{"reviewer"},
+			teams: []configTeam{{owner: "myorg", name: "platform"}},
+		},
+	}
+	reviewers, err := NewReviewers(ctx, newMemberships(client, false), rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requested := func(reviewer github.RequestedReviewer) *github.PullRequest {
+		pr := &github.PullRequest{}
+		pr.ReviewRequests.Nodes = append(pr.ReviewRequests.Nodes, github.ReviewRequest{RequestedReviewer: reviewer})
+		return pr
+	}
+	reviewed := func(login string) *github.PullRequest {
+		pr := &github.PullRequest{}
+		pr.LatestReviews.Nodes = append(pr.LatestReviews.Nodes, github.Review{Author: github.User{Login: login}, State: github.ReviewStateApproved})
+		return pr
+	}
+	team := func(org, slug string) github.RequestedReviewer {
+		r := github.RequestedReviewer{Type: github.ReviewerTypeTeam, Slug: slug}
+		r.Organization.Login = org
+		return r
+	}
+	user := func(login string) github.RequestedReviewer {
+		return github.RequestedReviewer{Type: github.ReviewerTypeUser, Login: login}
+	}
+
+	tests := []struct {
+		name string
+		pr   *github.PullRequest
+		want bool
+	}{
+		{"no reviewers", &github.PullRequest{}, false},
+		{"team requested", requested(team("myorg", "platform")), true},
+		{"other team requested", requested(team("myorg", "web")), false},
+		{"user requested", requested(user("reviewer")), true},
+		{"team member requested", requested(user("member")), true},
+		{"other user requested", requested(user("other")), false},
+		{"reviewed by team member", reviewed("member"), true},
+		{"reviewed by other user", reviewed("other"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reviewers.Resolve(ctx, tt.pr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.want, got; want != got {
+				t.Fatalf("Expected %t, got %t", want, got)
+			}
+		})
+	}
+
+	if want, got := 1, teamCalls; want != got {
+		t.Fatalf("Expected team members to be fetched %d times, got %d", want, got)
+	}
+
+	all, err := NewReviewers(ctx, newMemberships(client, false), configReviewers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := all.Resolve(ctx, &github.PullRequest{}); !ok {
+		t.Fatal("Expected all pull requests to be included without rules")
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// configPullRequestCondition matches pull requests.
+// All of the specified criteria have to match.
+type configPullRequestCondition struct {
+	states          []github.PullRequestState
+	draft           *bool
+	reviewDecisions []github.ReviewDecision
+	reviews         []github.ReviewState // Any reviewer's latest review.
+	reviewRequested *bool                // Has pending review requests.
+	checks          []github.CheckState
+}
+
+func (c *configPullRequestCondition) empty() bool {
+	return len(c.states) == 0 && c.draft == nil && len(c.reviewDecisions) == 0 &&
+		len(c.reviews) == 0 && c.reviewRequested == nil && len(c.checks) == 0
+}
+
+func (c *configPullRequestCondition) matches(pr *github.PullRequest) bool {
+	if len(c.states) > 0 && !slices.Contains(c.states, pr.State) {
+		return false
+	}
+	if c.draft != nil && *c.draft != pr.IsDraft {
+		return false
+	}
+	if len(c.reviewDecisions) > 0 && !slices.Contains(c.reviewDecisions, pr.ReviewDecision) {
+		return false
+	}
+	if len(c.reviews) > 0 && !pr.HasReview(c.reviews...) {
+		return false
+	}
+	if c.reviewRequested != nil && *c.reviewRequested != pr.IsReviewRequested() {
+		return false
+	}
+	if len(c.checks) > 0 && !slices.Contains(c.checks, pr.CheckState()) {
+		return false
+	}
+	return true
+}
+
+// configUpdateRule sets project field values of the pull requests matching the condition.
+type configUpdateRule struct {
+	when configPullRequestCondition
+	set  map[string]string
+}
+
+// updateRule is an update rule with field values resolved against the project fields.
+type updateRule struct {
+	when   configPullRequestCondition
+	fields []fieldValue
+}
+
+// matchUpdateRule returns the first rule matching the pull request.
+func matchUpdateRule(rules []updateRule, pr *github.PullRequest) (updateRule, bool) {
+	for _, rule := range rules {
+		if rule.when.matches(pr) {
+			return rule, true
+		}
+	}
+	return updateRule{}, false
+}
+
+// configPatterns filters values such as labels with include and exclude glob patterns.
+type configPatterns struct {
+	include []string
+	exclude []string
+}
+
+func (p *configPatterns) empty() bool {
+	return len(p.include) == 0 && len(p.exclude) == 0
+}
+
+// matches reports whether any of the values matches the include patterns, if there are any,
+// and none of the values matches the exclude patterns.
+func (p *configPatterns) matches(values []string) bool {
+	if len(p.include) > 0 && !matchAny(p.include, values) {
+		return false
+	}
+	return !matchAny(p.exclude, values)
+}
+
+func matchAny(patterns []string, values []string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deletesPullRequests reports whether the job has any pull request delete criteria.
+func deletesPullRequests(job configJob) bool {
+	return len(job.pullRequests.delete.states) > 0 ||
+		job.pullRequests.delete.drafts ||
+		job.pullRequests.delete.staleAfter > 0 ||
+		len(job.pullRequests.delete.reviewDecisions) > 0 ||
+		len(job.pullRequests.delete.reviews) > 0 ||
+		job.pullRequests.delete.reviewRequested != nil ||
+		len(job.pullRequests.delete.checks) > 0 ||
+		!job.pullRequests.delete.labels.empty() ||
+		!job.pullRequests.delete.baseBranches.empty() ||
+		!job.pullRequests.delete.headBranches.empty()
+}
+
+// pullRequestRule is a single add or delete criterion of a job.
+type pullRequestRule struct {
+	name    string
+	matches func(pr *github.PullRequest) bool
+}
+
+// addRules returns the configured criteria a pull request has to match to be added
+// besides the authors and reviewers.
+func addRules(job configJob, now time.Time) []pullRequestRule {
+	add := job.pullRequests.add
+
+	var rules []pullRequestRule
+	if len(add.states) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("state is one of %v", add.states), func(pr *github.PullRequest) bool {
+			return slices.Contains(add.states, pr.State)
+		}})
+	}
+	// Skip draft PRs.
+	if !add.drafts {
+		rules = append(rules, pullRequestRule{"not a draft", func(pr *github.PullRequest) bool {
+			return !pr.IsDraft
+		}})
+	}
+	if !add.labels.empty() {
+		rules = append(rules, pullRequestRule{"labels match", func(pr *github.PullRequest) bool {
+			return add.labels.matches(pr.LabelNames())
+		}})
+	}
+	if !add.baseBranches.empty() {
+		rules = append(rules, pullRequestRule{"base branch matches", func(pr *github.PullRequest) bool {
+			return add.baseBranches.matches([]string{pr.BaseRefName})
+		}})
+	}
+	if !add.headBranches.empty() {
+		rules = append(rules, pullRequestRule{"head branch matches", func(pr *github.PullRequest) bool {
+			return add.headBranches.matches([]string{pr.HeadRefName})
+		}})
+	}
+	if len(add.reviewDecisions) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("review decision is one of %v", add.reviewDecisions), func(pr *github.PullRequest) bool {
+			return slices.Contains(add.reviewDecisions, pr.ReviewDecision)
+		}})
+	}
+	if len(add.reviews) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("has a latest review in %v", add.reviews), func(pr *github.PullRequest) bool {
+			return pr.HasReview(add.reviews...)
+		}})
+	}
+	if add.reviewRequested != nil {
+		rules = append(rules, reviewRequestedRule(*add.reviewRequested))
+	}
+	if len(add.checks) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("checks are one of %v", add.checks), func(pr *github.PullRequest) bool {
+			return slices.Contains(add.checks, pr.CheckState())
+		}})
+	}
+	// Skip PRs created too long ago.
+	if add.createdWithin > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("created within %s", add.createdWithin), func(pr *github.PullRequest) bool {
+			return !pr.CreatedAt.Before(now.Add(-add.createdWithin))
+		}})
+	}
+	// Skip PRs from non-users (e.g. bots).
+	rules = append(rules, pullRequestRule{"author is a user", func(pr *github.PullRequest) bool {
+		return pr.Author.Type == github.AuthorTypeUser
+	}})
+
+	return rules
+}
+
+// deleteRules returns the configured criteria any of which
+// a pull request has to match to be deleted besides the authors.
+func deleteRules(job configJob, now time.Time) []pullRequestRule {
+	del := job.pullRequests.delete
+
+	var rules []pullRequestRule
+	if del.drafts {
+		rules = append(rules, pullRequestRule{"is a draft", func(pr *github.PullRequest) bool {
+			return pr.IsDraft
+		}})
+	}
+	if len(del.reviewDecisions) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("review decision is one of %v", del.reviewDecisions), func(pr *github.PullRequest) bool {
+			return slices.Contains(del.reviewDecisions, pr.ReviewDecision)
+		}})
+	}
+	if len(del.reviews) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("has a latest review in %v", del.reviews), func(pr *github.PullRequest) bool {
+			return pr.HasReview(del.reviews...)
+		}})
+	}
+	if del.reviewRequested != nil {
+		rules = append(rules, reviewRequestedRule(*del.reviewRequested))
+	}
+	if len(del.checks) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("checks are one of %v", del.checks), func(pr *github.PullRequest) bool {
+			return slices.Contains(del.checks, pr.CheckState())
+		}})
+	}
+	// Stale PRs haven't been updated for too long.
+	if del.staleAfter > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("not updated for %s", del.staleAfter), func(pr *github.PullRequest) bool {
+			return pr.UpdatedAt.Before(now.Add(-del.staleAfter))
+		}})
+	}
+	for _, filter := range []struct {
+		name     string
+		patterns configPatterns
+		values   func(pr *github.PullRequest) []string
+	}{
+		{"labels", del.labels, func(pr *github.PullRequest) []string { return pr.LabelNames() }},
+		{"base branch", del.baseBranches, func(pr *github.PullRequest) []string { return []string{pr.BaseRefName} }},
+		{"head branch", del.headBranches, func(pr *github.PullRequest) []string { return []string{pr.HeadRefName} }},
+	} {
+		if filter.patterns.empty() {
+			continue
+		}
+		rules = append(rules, pullRequestRule{filter.name + " no longer match", func(pr *github.PullRequest) bool {
+			return !filter.patterns.matches(filter.values(pr))
+		}})
+	}
+	if len(del.states) > 0 {
+		rules = append(rules, pullRequestRule{fmt.Sprintf("state is one of %v", del.states), func(pr *github.PullRequest) bool {
+			return slices.Contains(del.states, pr.State)
+		}})
+	}
+
+	return rules
+}
+
+// reviewRequestedRule matches pull requests with or without pending review requests.
+func reviewRequestedRule(requested bool) pullRequestRule {
+	name := "has pending review requests"
+	if !requested {
+		name = "has no pending review requests"
+	}
+	return pullRequestRule{name, func(pr *github.PullRequest) bool {
+		return pr.IsReviewRequested() == requested
+	}}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTokenTTL is how long a token read from a file or a command is used before reading it again.
+const defaultTokenTTL = 10 * time.Minute
+
+// errNoToken is returned when a token source is not configured or has no token.
+var errNoToken = errors.New("no token")
+
+// newTokenSource returns the source of tokens to authenticate with.
+// The first configured source wins in the following order:
+//  1. -token-file flag
+//  2. GitHub App installation
+//  3. tokenCommand
+//  4. GITHUB_TOKEN
+//  5. gh CLI hosts.yml
+func newTokenSource(ctx context.Context, cfg config) (oauth2.TokenSource, error) {
+	if cfg.tokenFile != "" {
+		fmt.Fprintf(cfg.out(), "  Token: file %s\n", cfg.tokenFile)
+		return newRefreshingTokenSource(cfg.githubTokenTTL, func() (string, error) {
+			return readTokenFile(cfg.tokenFile)
+		})
+	}
+
+	if cfg.githubApp != nil {
+		keyRaw, err := os.ReadFile(cfg.githubApp.privateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading GitHub App private key %s: %w", cfg.githubApp.privateKeyPath, err)
+		}
+		key, err := github.ParsePrivateKey(keyRaw)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing GitHub App private key %s: %w", cfg.githubApp.privateKeyPath, err)
+		}
+
+		fmt.Fprintf(cfg.out(), "  Token: GitHub App %d installation %d\n", cfg.githubApp.id, cfg.githubApp.installationID)
+
+		return github.NewAppTokenSource(ctx, &http.Client{Timeout: httpTimeout}, cfg.githubURL, cfg.githubApp.id, cfg.githubApp.installationID, key), nil
+	}
+
+	if cfg.githubTokenCommand != "" {
+		fmt.Fprintln(cfg.out(), "  Token: command")
+		return newRefreshingTokenSource(cfg.githubTokenTTL, func() (string, error) {
+			return runTokenCommand(ctx, cfg.githubTokenCommand)
+		})
+	}
+
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		fmt.Fprintln(cfg.out(), "  Token: GITHUB_TOKEN")
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+
+	host := githubHost(cfg.githubURL)
+	token, err := readGHToken(ghHostsPath(), host)
+	if errors.Is(err, errNoToken) {
+		return nil, fmt.Errorf("a token is required: use -token-file, github.app, github.tokenCommand, GITHUB_TOKEN or gh auth login")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(cfg.out(), "  Token: gh CLI %s\n", host)
+
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+}
+
+// newRefreshingTokenSource returns a token source that fetches the token again after the TTL.
+// The first token is fetched right away to fail early.
+func newRefreshingTokenSource(ttl time.Duration, fetch func() (string, error)) (oauth2.TokenSource, error) {
+	source := &fetchTokenSource{ttl: ttl, fetch: fetch, now: time.Now}
+
+	token, err := source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth2.ReuseTokenSource(token, source), nil
+}
+
+type fetchTokenSource struct {
+	ttl   time.Duration
+	fetch func() (string, error)
+	now   func() time.Time
+}
+
+func (s *fetchTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{AccessToken: token, Expiry: s.now().Add(s.ttl)}, nil
+}
+
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file %s: %w", path, err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+// runTokenCommand runs the command with the shell and returns its output as the token.
+func runTokenCommand(ctx context.Context, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("error running token command: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("error running token command: %w", err)
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("token command printed no token")
+	}
+
+	return token, nil
+}
+
+// githubHost returns the host the gh CLI knows the API endpoint by.
+func githubHost(githubURL string) string {
+	u, err := url.Parse(githubURL)
+	if err != nil || u.Host == "" {
+		return "github.com"
+	}
+	if u.Host == "api.github.com" {
+		return "github.com"
+	}
+
+	return u.Host
+}
+
+// ghHostsPath returns the location of the gh CLI hosts.yml.
+// See https://cli.github.com/manual/gh_help_environment
+func ghHostsPath() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// readGHToken reads the token of the host from the gh CLI hosts.yml.
+// Tokens kept by gh in the system keyring are not available.
+func readGHToken(path, host string) (string, error) {
+	if path == "" {
+		return "", errNoToken
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errNoToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading gh CLI config %s: %w", path, err)
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", fmt.Errorf("error parsing gh CLI config %s: %w", path, err)
+	}
+
+	token := hosts[host].OAuthToken
+	if token == "" {
+		return "", errNoToken
+	}
+
+	return token, nil
+}
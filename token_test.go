@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTokenSourcePrecedence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte("github.com:\n  oauth_token: gh-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GH_CONFIG_DIR", dir)
+
+	tests := []struct {
+		name  string
+		cfg   config
+		env   string
+		token string
+	}{
+		{"token file", config{tokenFile: tokenFile, githubTokenCommand: "echo command-token"}, "env-token", "file-token"},
+		{"token command", config{githubTokenCommand: "echo command-token"}, "env-token", "command-token"},
+		{"environment", config{}, "env-token", "env-token"},
+		{"gh cli", config{}, "", "gh-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", tt.env)
+			tt.cfg.githubURL, tt.cfg.githubTokenTTL = "https://api.github.com", defaultTokenTTL
+
+			source, err := newTokenSource(ctx, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := source.Token()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := tt.token, token.AccessToken; want != got {
+				t.Fatalf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestNewTokenSourceNoToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+
+	if _, err := newTokenSource(context.Background(), config{githubURL: "https://api.github.com"}); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	var fetches int
+	fetch := func() (string, error) {
+		fetches++
+		return "token", nil
+	}
+
+	// A token within its TTL is reused.
+	source, err := newRefreshingTokenSource(defaultTokenTTL, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if _, err := source.Token(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want, got := 1, fetches; want != got {
+		t.Fatalf("Expected %d fetches, got %d", want, got)
+	}
+
+	// An expired token is fetched again.
+	fetches = 0
+	source, err = newRefreshingTokenSource(0, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if _, err := source.Token(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want, got := 4, fetches; want != got {
+		t.Fatalf("Expected %d fetches, got %d", want, got)
+	}
+}
+
+func TestRunTokenCommand(t *testing.T) {
+	ctx := context.Background()
+
+	token, err := runTokenCommand(ctx, "printf '  token\\n'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "token", token; want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+
+	if _, err := runTokenCommand(ctx, "echo denied >&2; exit 1"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if _, err := runTokenCommand(ctx, "true"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestReadGHToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.yml")
+	if err := os.WriteFile(path, []byte(`
+github.com:
+    user: user
+    oauth_token: gho_token
+    git_protocol: https
+github.example.com:
+    user: user
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := readGHToken(path, githubHost("https://api.github.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "gho_token", token; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+
+	// The token is kept in the keyring.
+	if _, err := readGHToken(path, githubHost("https://github.example.com/api")); !errors.Is(err, errNoToken) {
+		t.Fatalf("Expected %v, got %v", errNoToken, err)
+	}
+
+	if _, err := readGHToken(filepath.Join(t.TempDir(), "hosts.yml"), "github.com"); !errors.Is(err, errNoToken) {
+		t.Fatalf("Expected %v, got %v", errNoToken, err)
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+// maxPayloadSize is the maximum size of a webhook payload GitHub delivers.
+const maxPayloadSize = 25 << 20
+
+// serve runs an HTTP server that syncs pull requests on webhook deliveries.
+func serve(ctx context.Context, args []string) error {
+	var (
+		configPath      string
+		tokenFile       string
+		dryRun, verbose bool
+		listen          string
+		cacheTTL        time.Duration
+	)
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", "config.yaml", "Path to the config file")
+	flags.StringVar(&tokenFile, "token-file", "", "Path to a file with the GitHub token")
+	flags.BoolVar(&dryRun, "dry-run", false, "Dry run")
+	flags.BoolVar(&verbose, "verbose", false, "Verbose output")
+	flags.StringVar(&listen, "listen", ":8080", "Address to listen on")
+	flags.DurationVar(&cacheTTL, "cache-ttl", time.Hour, "How long to cache team and organization memberships")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	if secret == "" {
+		return fmt.Errorf("GITHUB_WEBHOOK_SECRET is required")
+	}
+
+	cfg, client, err := setup(ctx, os.Stdout, configPath, tokenFile, dryRun, verbose)
+	if err != nil {
+		return err
+	}
+
+	memberships := newMemberships(client, cfg.verbose)
+	memberships.ttl = cacheTTL
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhook", newWebhookHandler(client, cfg, memberships, []byte(secret)))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "OK")
+	})
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: httpTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("  Listening on %s\n", listen)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	fmt.Println("Stopped")
+
+	return nil
+}
+
+// pullRequestEvent is the part of the pull_request webhook payload used to find the pull request.
+// The pull request itself is always fetched to make decisions on its current state.
+type pullRequestEvent struct {
+	Action     string `json:"action"`
+	Number     int    `json:"number"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Archived bool     `json:"archived"`
+		Fork     bool     `json:"fork"`
+		Topics   []string `json:"topics"`
+	} `json:"repository"`
+}
+
+// webhookHandler handles GitHub webhook deliveries.
+type webhookHandler struct {
+	client      githubClient
+	cfg         config
+	memberships *memberships
+	secret      []byte
+
+	mu sync.Mutex // Deliveries are processed one at a time.
+}
+
+func newWebhookHandler(client githubClient, cfg config, memberships *memberships, secret []byte) *webhookHandler {
+	return &webhookHandler{
+		client:      client,
+		cfg:         cfg,
+		memberships: memberships,
+		secret:      secret,
+	}
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "error reading payload", http.StatusBadRequest)
+		return
+	}
+
+	if !validSignature(h.secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		fmt.Fprintln(w, "pong")
+		return
+	case "pull_request":
+	default:
+		fmt.Fprintf(w, "ignored %s event\n", event)
+		return
+	}
+
+	var event pullRequestEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		http.Error(w, "error decoding payload", http.StatusBadRequest)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	decisions, err := h.handlePullRequestEvent(r.Context(), event)
+	if err != nil {
+		fmt.Fprintf(h.cfg.out(), "Webhook %s failed: %s\n", r.Header.Get("X-GitHub-Delivery"), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, decision := range decisions {
+		fmt.Fprintln(w, decision)
+	}
+}
+
+// handlePullRequestEvent syncs the pull request with the projects of all jobs
+// that include its repository and returns the decision made for each job.
+func (h *webhookHandler) handlePullRequestEvent(ctx context.Context, event pullRequestEvent) ([]string, error) {
+	owner, name := event.Repository.Owner.Login, event.Repository.Name
+
+	fmt.Fprintf(h.cfg.out(), "Pull request: %s/%s#%d %s\n", owner, name, event.Number, event.Action)
+
+	h.memberships.expire(time.Now())
+
+	repo := &github.Repository{Name: name, IsArchived: event.Repository.Archived, IsFork: event.Repository.Fork}
+	repo.Owner.Login = owner
+
+	var decisions []string
+	for _, job := range h.cfg.jobs {
+		included, err := includesRepo(ctx, h.client, job, repo, event.Repository.Topics)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.name, err)
+		}
+		if !included {
+			continue
+		}
+
+		if len(h.cfg.jobs) > 1 {
+			fmt.Fprintf(h.cfg.out(), "Job: %s\n", job.name)
+		}
+
+		decision, err := syncPullRequest(ctx, h.client, h.cfg, job, h.memberships, owner, name, event.Number)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.name, err)
+		}
+		decisions = append(decisions, job.name+": "+decision)
+	}
+
+	if len(decisions) == 0 {
+		fmt.Fprintln(h.cfg.out(), "  No matching jobs")
+		decisions = append(decisions, "no matching jobs")
+	}
+
+	return decisions, nil
+}
+
+// validSignature verifies the HMAC-SHA256 signature of the payload
+// sent in the X-Hub-Signature-256 header.
+func validSignature(secret, payload []byte, signature string) bool {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// syncPullRequest makes the add, update or delete decision for a single pull request
+// using the same rules as a full sync and returns the decision.
+func syncPullRequest(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	memberships *memberships,
+	owner, name string,
+	number int,
+) (string, error) {
+	authors, err := NewAuthors(ctx, memberships, job.authors)
+	if err != nil {
+		return "", err
+	}
+
+	reviewers, err := NewReviewers(ctx, memberships, job.reviewers)
+	if err != nil {
+		return "", err
+	}
+
+	project, err := client.GetProject(ctx, job.project.owner, job.project.ownerType, job.project.number)
+	if err != nil {
+		return "", err
+	}
+
+	pr, err := client.GetPullRequest(ctx, owner, name, number)
+	if err != nil {
+		return "", fmt.Errorf("error fetching pull request %s/%s#%d: %w", owner, name, number, err)
+	}
+
+	fields, err := resolveJobFields(ctx, client, job, project)
+	if err != nil {
+		return "", err
+	}
+
+	decision, err := decidePullRequest(ctx, client, cfg, job, authors, reviewers, project, pr, fields)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(cfg.out(), "  - %s %s %s %s %s %s\n", pr.URL, pr.Author.Login, pr.Title, pr.State, draftState(pr.IsDraft), strings.ToUpper(decision))
+
+	return decision, nil
+}
+
+// decidePullRequest adds the pull request to the project if it's not there yet,
+// otherwise updates and then deletes (archives) its project item,
+// in the same order as the phases of a full sync.
+func decidePullRequest(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	reviewers reviewerResolver,
+	project *github.Project,
+	pr *github.PullRequest,
+	fields jobFields,
+) (string, error) {
+	now := time.Now()
+
+	item := pr.FindProjectItem(project.ID)
+	if item == nil {
+		ok, err := isAddCandidate(ctx, job, authors, reviewers, pr, now)
+		if err != nil || !ok {
+			return "skip", err
+		}
+
+		if err := addPullRequest(ctx, client, cfg, job, authors, project, pr, fields.addPullRequests); err != nil {
+			return "", err
+		}
+		return "add", nil
+	}
+	pr.ProjectItemID, pr.ProjectItem = item.ID, item
+
+	decision := decisionKeep
+
+	updated, err := updatePullRequestItem(ctx, client, cfg, job, authors, project, pr, fields.updateRules)
+	if err != nil {
+		return "", err
+	}
+	if updated {
+		decision = decisionUpdate
+	}
+
+	if !deletesPullRequests(job) {
+		return decision, nil
+	}
+
+	deleteDecision, _, err := pullRequestDeleteDecision(ctx, job, authors, pr, now)
+	if err != nil {
+		return "", err
+	}
+	if deleteDecision != decisionDelete && deleteDecision != decisionArchive {
+		return decision, nil
+	}
+
+	if err := removeProjectItem(ctx, client, cfg, project, pullRequestContent(pr), deleteDecision == decisionArchive); err != nil {
+		return "", err
+	}
+
+	return deleteDecision, nil
+}
+
+// updatePullRequestItem sets the field values of the first matching update rule
+// on the project item of the pull request and reports whether anything changed.
+func updatePullRequestItem(
+	ctx context.Context,
+	client githubClient,
+	cfg config,
+	job configJob,
+	authors authorResolver,
+	project *github.Project,
+	pr *github.PullRequest,
+	rules []updateRule,
+) (bool, error) {
+	changes, _, err := pullRequestFieldChanges(ctx, job, authors, rules, pr)
+	if err != nil || len(changes) == 0 {
+		return false, err
+	}
+
+	if err := setProjectItemFields(ctx, client, cfg, project, pullRequestContent(pr), changes); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
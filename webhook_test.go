@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pmatseykanets/prsync/github"
+)
+
+func TestWebhookHandlerSignature(t *testing.T) {
+	payload, err := os.ReadFile("testdata/pull_request_opened.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := newWebhookHandler(&fakeGithubClient{}, config{}, newMemberships(&fakeGithubClient{}, false), []byte("secret"))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for _, signature := range []string{"", "sha256=", "sha256=zz", signPayload([]byte("other"), payload)} {
+		resp := postWebhook(t, server.URL, "pull_request", signature, payload)
+		if want, got := http.StatusUnauthorized, resp.StatusCode; want != got {
+			t.Fatalf("Expected %d for signature %q, got %d", want, signature, got)
+		}
+	}
+
+	resp := postWebhook(t, server.URL, "ping", signPayload([]byte("secret"), payload), payload)
+	if want, got := http.StatusOK, resp.StatusCode; want != got {
+		t.Fatalf("Expected %d, got %d", want, got)
+	}
+}
+
+func TestWebhookHandlerPullRequest(t *testing.T) {
+	payload, err := os.ReadFile("testdata/pull_request_opened.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var added []string
+	client := &fakeGithubClient{
+		GetProjectFunc: func(ctx context.Context, owner string, ownerType github.ProjectOwnerType, number int) (*github.Project, error) {
+			return &github.Project{ID: "project", Number: number}, nil
+		},
+		GetPullRequestFunc: func(ctx context.Context, owner, name string, number int) (*github.PullRequest, error) {
+			if want, got := "org/repo#42", owner+"/"+name+"#"+strconv.Itoa(number); want != got {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+			pr := &github.PullRequest{ID: "PR_42", Number: number, State: github.PullRequestStateOpen}
+			pr.Author.Type = github.AuthorTypeUser
+			pr.Author.Login = "user"
+			return pr, nil
+		},
+		AddPullRequestToProjectFunc: func(ctx context.Context, projectID, prID string) (string, error) {
+			added = append(added, prID)
+			return "item", nil
+		},
+	}
+
+	cfg := config{
+		jobs: []configJob{
+			{
+				name:        "backend",
+				repoSources: []configRepoSource{{kind: repoSourceTopic, owner: "org", name: "backend"}},
+				authors:     configAuthors{include: configAuthorRules{users: []string{"user"}}},
+			},
+			{
+				name:        "frontend",
+				repoSources: []configRepoSource{{kind: repoSourceName, owner: "org", name: "web"}},
+			},
+		},
+	}
+	cfg.jobs[0].pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
+
+	server := httptest.NewServer(newWebhookHandler(client, cfg, newMemberships(client, false), []byte("secret")))
+	defer server.Close()
+
+	resp := postWebhook(t, server.URL, "pull_request", signPayload([]byte("secret"), payload), payload)
+	body, _ := io.ReadAll(resp.Body)
+	if want, got := http.StatusOK, resp.StatusCode; want != got {
+		t.Fatalf("Expected %d, got %d: %s", want, got, body)
+	}
+
+	if want, got := "backend: add", strings.TrimSpace(string(body)); want != got {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+	if want, got := 1, len(added); want != got {
+		t.Fatalf("Expected %d added, got %d", want, got)
+	}
+	if want, got := "PR_42", added[0]; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestDecidePullRequest(t *testing.T) {
+	ctx := context.Background()
+	project := &github.Project{ID: "project"}
+
+	newPR := func(state github.PullRequestState, inProject bool) *github.PullRequest {
+		pr := &github.PullRequest{ID: "PR", State: state}
+		pr.Author.Type = github.AuthorTypeUser
+		pr.Author.Login = "user"
+		if inProject {
+			pr.ProjectItems.Nodes = []github.ProjectItem{{ID: "item", Project: project}}
+		}
+		return pr
+	}
+
+	job := configJob{}
+	job.pullRequests.add.states = []github.PullRequestState{github.PullRequestStateOpen}
+	job.pullRequests.delete.states = []github.PullRequestState{github.PullRequestStateMerged}
+	job.pullRequests.delete.allAuthors = true
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pr       *github.PullRequest
+		decision string
+	}{
+		{newPR(github.PullRequestStateOpen, false), "add"},
+		{newPR(github.PullRequestStateClosed, false), "skip"},
+		{newPR(github.PullRequestStateOpen, true), "keep"},
+		{newPR(github.PullRequestStateMerged, true), "delete"},
+	}
+
+	for _, tt := range tests {
+		var deleted string
+		client := &fakeGithubClient{
+			DeleteProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+				deleted = projectItemID
+				return nil
+			},
+		}
+
+		decision, err := decidePullRequest(ctx, client, config{}, job, authors, &reviewers{}, project, tt.pr, jobFields{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := tt.decision, decision; want != got {
+			t.Fatalf("Expected %s for %s, got %s", want, tt.pr.State, got)
+		}
+		if tt.decision == "delete" {
+			if want, got := "item", deleted; want != got {
+				t.Fatalf("Expected %s deleted, got %s", want, got)
+			}
+		}
+	}
+}
+
+func TestDecidePullRequestUpdatesBeforeDelete(t *testing.T) {
+	ctx := context.Background()
+	project := &github.Project{ID: "project"}
+
+	pr := &github.PullRequest{ID: "PR", State: github.PullRequestStateMerged}
+	pr.Author.Type = github.AuthorTypeUser
+	pr.Author.Login = "user"
+	pr.ProjectItems.Nodes = []github.ProjectItem{{ID: "item", Project: project}}
+
+	job := configJob{}
+	job.pullRequests.delete.states = []github.PullRequestState{github.PullRequestStateMerged}
+	job.pullRequests.delete.allAuthors = true
+	job.pullRequests.delete.mode = deleteModeArchive
+	job.pullRequests.update.allAuthors = true
+
+	authors, err := NewAuthors(ctx, newMemberships(&fakeGithubClient{}, false), configAuthors{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := "done"
+	fields := jobFields{
+		updateRules: []updateRule{{
+			when:   configPullRequestCondition{states: []github.PullRequestState{github.PullRequestStateMerged}},
+			fields: []fieldValue{{field: github.ProjectField{ID: "status"}, value: github.ProjectFieldValue{SingleSelectOptionID: &done}}},
+		}},
+	}
+
+	// A full sync runs the update phase before the delete phase.
+	var calls []string
+	client := &fakeGithubClient{
+		ArchiveProjectItemFunc: func(ctx context.Context, projectID, projectItemID string) error {
+			calls = append(calls, "archive "+projectItemID)
+			return nil
+		},
+		UpdateProjectItemFieldValueFunc: func(ctx context.Context, projectID, projectItemID, fieldID string, value github.ProjectFieldValue) error {
+			calls = append(calls, "update "+projectItemID+" "+fieldID)
+			return nil
+		},
+	}
+
+	decision, err := decidePullRequest(ctx, client, config{}, job, authors, &reviewers{}, project, pr, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "archive", decision; want != got {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if want := []string{"update item status", "archive item"}; !slices.Equal(want, calls) {
+		t.Fatalf("Expected %v, got %v", want, calls)
+	}
+}
+
+func signPayload(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(t *testing.T, url, event, signature string, payload []byte) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}